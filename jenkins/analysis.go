@@ -0,0 +1,133 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const issuesFileSuffix = "-issues.xml"
+
+type resultActionXML struct {
+	ID     string            `xml:"id"`
+	Result analysisResultXML `xml:"result"`
+}
+
+type analysisResultXML struct {
+	ID              string             `xml:"id"`
+	SizePerSeverity []severityCountXML `xml:"sizePerSeverity>entry"`
+}
+
+type severityCountXML struct {
+	Severity severityXML `xml:"edu.hm.hafner.analysis.Severity"`
+	Name     string      `xml:"string"`
+	Count    int         `xml:"int"`
+}
+
+type severityXML struct {
+	Name  string `xml:"name"`
+	Value string `xml:",chardata"`
+}
+
+func (severity severityXML) String() string {
+	if severity.Name != "" {
+		return strings.ToLower(severity.Name)
+	}
+	return strings.ToLower(strings.TrimSpace(severity.Value))
+}
+
+// parseStaticAnalysis returns the number of issues per tool and severity that the Warnings Next Generation plugin
+// recorded for a build. The totals of the ResultAction in build.xml are used when available, tools without one are
+// counted from their <tool>-issues.xml file. Tools whose file can't be parsed are left out, and the last error is
+// returned with the issues of the other tools.
+func parseStaticAnalysis(build *buildXML, buildPath string) (map[string]map[string]int, error) {
+	issues := make(map[string]map[string]int)
+	var parseErr error
+
+	for _, action := range build.Actions.WarningsResults {
+		tool := action.ID
+		if tool == "" {
+			tool = action.Result.ID
+		}
+
+		issues[tool] = make(map[string]int)
+		for _, entry := range action.Result.SizePerSeverity {
+			severity := entry.Severity.String()
+			if severity == "" {
+				severity = strings.ToLower(entry.Name)
+			}
+			issues[tool][severity] += entry.Count
+		}
+	}
+
+	issuesFiles, err := filepath.Glob(filepath.Join(buildPath, "*"+issuesFileSuffix))
+	if err != nil {
+		return issues, err
+	}
+
+	for _, issuesFile := range issuesFiles {
+		tool := strings.TrimSuffix(filepath.Base(issuesFile), issuesFileSuffix)
+		if _, ok := issues[tool]; ok {
+			continue
+		}
+
+		counts, err := countIssues(issuesFile)
+		if err != nil {
+			// the counts of a tool are only known when the whole file was read, the other tools are still returned
+			parseErr = err
+			continue
+		}
+		issues[tool] = counts
+	}
+
+	return issues, parseErr
+}
+
+// countIssues streams through an issues file and counts the issues per severity, without keeping the issues in memory.
+func countIssues(path string) (map[string]int, error) {
+	counts := make(map[string]int)
+
+	file, err := os.Open(path)
+	if err != nil {
+		return counts, err
+	}
+	defer file.Close()
+
+	decoder := newXMLDecoder(file)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return counts, nil
+		}
+		if err != nil {
+			return counts, err
+		}
+
+		element, ok := token.(xml.StartElement)
+		if !ok || element.Name.Local != "severity" {
+			continue
+		}
+
+		var severity severityXML
+		err = decoder.DecodeElement(&severity, &element)
+		if err != nil {
+			return counts, err
+		}
+		counts[severity.String()]++
+	}
+}
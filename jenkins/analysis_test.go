@@ -0,0 +1,69 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import "testing"
+
+func TestStaticAnalysisIssues(t *testing.T) {
+	build, err := parseBuild(pipelineJob + "/builds/3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	build.loadIssues()
+
+	expected := map[string]map[string]int{
+		"java":       {"high": 1, "normal": 4},
+		"checkstyle": {"normal": 2, "low": 1},
+	}
+
+	if len(build.Issues) != len(expected) {
+		t.Errorf("build.Issues is %v, expected %v", build.Issues, expected)
+	}
+
+	for tool, severities := range expected {
+		for severity, count := range severities {
+			if build.Issues[tool][severity] != count {
+				t.Errorf("build.Issues[%s][%s] is %d, expected %d", tool, severity, build.Issues[tool][severity], count)
+			}
+		}
+	}
+}
+
+func TestNoStaticAnalysisIssues(t *testing.T) {
+	build, err := parseBuild(testbuild)
+	if err != nil {
+		t.Fatal(err)
+	}
+	build.loadIssues()
+
+	if len(build.Issues) != 0 {
+		t.Errorf("build.Issues is %v, expected no issues", build.Issues)
+	}
+}
+
+func TestMalformedIssuesFile(t *testing.T) {
+	build, err := parseBuild(malformedBuild)
+	if err != nil {
+		t.Fatal(err)
+	}
+	build.loadIssues()
+
+	if build.Issues["pmd"]["high"] != 2 {
+		t.Errorf("build.Issues[pmd][high] is %d, expected %d", build.Issues["pmd"]["high"], 2)
+	}
+
+	if _, ok := build.Issues["checkstyle"]; ok {
+		t.Errorf("build.Issues[checkstyle] is %v, expected no issues", build.Issues["checkstyle"])
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// changeRequestName matches the names the branch sources give to pull and merge requests.
+var changeRequestName = regexp.MustCompile(`^(PR|MR)-\d+$`)
+
+type branchConfigXML struct {
+	Head branchHeadXML `xml:"properties>org.jenkinsci.plugins.workflow.multibranch.BranchJobProperty>branch>head"`
+}
+
+type branchHeadXML struct {
+	Class string `xml:"class,attr"`
+	Name  string `xml:"name"`
+}
+
+// parseBranch returns the name of a multibranch branch job and whether it builds a change request.
+// The directory name of a branch is mangled, Jenkins keeps the original name in name-utf8.txt next to it.
+func parseBranch(path string) (string, bool) {
+	name := filepath.Base(path)
+	if utf8Name, err := ioutil.ReadFile(filepath.Join(path, "name-utf8.txt")); err == nil {
+		name = strings.TrimSpace(string(utf8Name))
+	}
+
+	var config branchConfigXML
+	if byteValue, err := ioutil.ReadFile(filepath.Join(path, "config.xml")); err == nil {
+		xml.Unmarshal(forceXMLVersion(byteValue), &config)
+	}
+
+	return name, isChangeRequest(name, config.Head.Class)
+}
+
+func isChangeRequest(name, headClass string) bool {
+	for _, kind := range []string{"PullRequest", "MergeRequest", "ChangeRequest"} {
+		if strings.Contains(headClass, kind) {
+			return true
+		}
+	}
+	return changeRequestName.MatchString(name)
+}
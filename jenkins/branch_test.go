@@ -0,0 +1,88 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import "testing"
+
+var (
+	featureBranch = "testdata/jobs/multibranch/branches/feature-login.7ajqb1"
+	pullRequest   = "testdata/jobs/multibranch/branches/PR-42"
+)
+
+func TestBranchJob(t *testing.T) {
+	job := Job{
+		path: JobPath(featureBranch),
+	}
+
+	err := job.fetch()
+	if err != nil {
+		t.Error(err)
+	}
+
+	if job.Name != "multibranch" {
+		t.Errorf("job.Name is %s, expected %s", job.Name, "multibranch")
+	}
+
+	if job.Folder != "/" {
+		t.Errorf("job.Folder is %s, expected %s", job.Folder, "/")
+	}
+
+	if job.Branch != "feature/login" {
+		t.Errorf("job.Branch is %s, expected %s", job.Branch, "feature/login")
+	}
+
+	if job.IsPullRequest {
+		t.Error("job.IsPullRequest is true, expected false")
+	}
+}
+
+func TestPullRequestJob(t *testing.T) {
+	job := Job{
+		path: JobPath(pullRequest),
+	}
+
+	err := job.fetch()
+	if err != nil {
+		t.Error(err)
+	}
+
+	if job.Branch != "PR-42" {
+		t.Errorf("job.Branch is %s, expected %s", job.Branch, "PR-42")
+	}
+
+	if !job.IsPullRequest {
+		t.Error("job.IsPullRequest is false, expected true")
+	}
+}
+
+func TestIsChangeRequest(t *testing.T) {
+	tests := []struct {
+		name      string
+		headClass string
+		expected  bool
+	}{
+		{"main", "jenkins.plugins.git.GitBranchSCMHead", false},
+		{"PR-7", "", true},
+		{"MR-12", "", true},
+		{"PR-fix", "", false},
+		{"feature", "io.jenkins.plugins.gitlabbranchsource.MergeRequestSCMHead", true},
+		{"12", "com.cloudbees.jenkins.plugins.bitbucket.PullRequestSCMHead", true},
+	}
+
+	for _, test := range tests {
+		if isChangeRequest(test.name, test.headClass) != test.expected {
+			t.Errorf("isChangeRequest(%s, %s) is %t, expected %t", test.name, test.headClass, !test.expected, test.expected)
+		}
+	}
+}
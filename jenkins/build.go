@@ -14,36 +14,71 @@
 package jenkins
 
 import (
+	"bufio"
 	"encoding/xml"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+
+	"github.com/prometheus/common/log"
 )
 
 // Build represents a particular build of a Job.
 type Build struct {
-	raw       buildXML
-	Number    int
-	Timestamp int
-	Duration  int
-	Result    string
-	EnvVars   map[string]string
+	raw              buildXML
+	path             string
+	flyweight        bool
+	Number           int
+	Timestamp        int
+	StartTime        int
+	Duration         int
+	Result           string
+	EnvVars          map[string]string
+	PipelineFailures []PipelineFailure
+	Coverage         map[string]float64
+	Issues           map[string]map[string]int
+	Tests            *TestResult
+	UpstreamCauses   []UpstreamCause
+	// BuiltOn is the node the build ran on, BuiltInNode for the controller. It is empty for builds that didn't run on a
+	// node themselves, like pipelines, which allocate nodes in their steps.
+	BuiltOn string
+}
+
+// UpstreamCause is the build of another job that triggered a build.
+type UpstreamCause struct {
+	Project string
+	Build   int
 }
 
 type buildXML struct {
+	XMLName   xml.Name
 	Result    string     `xml:"result"`
 	Timestamp int        `xml:"timestamp"`
+	StartTime int        `xml:"startTime"`
 	Duration  int        `xml:"duration"`
 	Number    int        `xml:"number"`
+	BuiltOn   *string    `xml:"builtOn"`
 	Actions   actionsXML `xml:"actions"`
 }
 
 type actionsXML struct {
-	XMLName          xml.Name            `xml:"actions"`
-	BuildEnvironment buildEnvironmentXML `xml:"org.jenkinsci.plugins.buildenvironment.actions.BuildEnvironmentBuildAction"`
+	XMLName          xml.Name               `xml:"actions"`
+	BuildEnvironment buildEnvironmentXML    `xml:"org.jenkinsci.plugins.buildenvironment.actions.BuildEnvironmentBuildAction"`
+	Jacoco           jacocoBuildActionXML   `xml:"hudson.plugins.jacoco.JacocoBuildAction"`
+	CodeCoverage     coverageBuildActionXML `xml:"io.jenkins.plugins.coverage.metrics.steps.CoverageBuildAction"`
+	WarningsResults  []resultActionXML      `xml:"io.jenkins.plugins.analysis.core.model.ResultAction"`
+	TestResult       *testResultActionXML   `xml:"hudson.tasks.junit.TestResultAction"`
+	UpstreamCauses   []upstreamCauseXML     `xml:"hudson.model.CauseAction>causeBag>entry>hudson.model.Cause_-UpstreamCause"`
+	LegacyCauses     []upstreamCauseXML     `xml:"hudson.model.CauseAction>causes>hudson.model.Cause_-UpstreamCause"`
+}
+
+type upstreamCauseXML struct {
+	Project string `xml:"upstreamProject"`
+	Build   int    `xml:"upstreamBuild"`
 }
 
 type buildEnvironmentXML struct {
@@ -101,26 +136,82 @@ func newBuildFromXML(path string) (Build, error) {
 	build.EnvVars = build.raw.getEnvVars()
 	buildNumber, err := strconv.ParseInt(filepath.Base(filepath.Dir(path)), 0, 0)
 	if err != nil {
-		return build, fmt.Errorf("couldn't parse build number %s: %v", buildNumber, err)
+		return build, fmt.Errorf("couldn't parse build number %s: %v", filepath.Base(filepath.Dir(path)), err)
 	}
 	build.Number = int(buildNumber)
 	build.Timestamp = build.raw.Timestamp
+	build.StartTime = build.raw.StartTime
+	if build.StartTime == 0 {
+		build.StartTime = build.Timestamp
+	}
 	build.Duration = build.raw.Duration
 	build.Result = build.raw.Result
+	build.path = filepath.Dir(path)
+	// flyweight builds don't take an executor: the parent of matrix configurations runs on a one-off executor, and Maven
+	// modules run in the executor of their project
+	build.flyweight = build.raw.XMLName.Local == "matrix-build" || build.raw.XMLName.Local == "maven-build"
+	if build.raw.BuiltOn != nil {
+		build.BuiltOn = *build.raw.BuiltOn
+		if build.BuiltOn == "" {
+			build.BuiltOn = BuiltInNode
+		}
+	}
+	build.Tests = parseTestResult(build.raw.Actions.TestResult)
+	// older versions of Jenkins stored the causes in a list instead of a bag
+	for _, cause := range append(build.raw.Actions.UpstreamCauses, build.raw.Actions.LegacyCauses...) {
+		build.UpstreamCauses = append(build.UpstreamCauses, UpstreamCause{Project: cause.Project, Build: cause.Build})
+	}
 
 	return build, nil
 }
 
+// loadCoverage parses the coverage ratios of the build. Only the coverage of the last successful build is exported, so
+// it isn't parsed for every build. A coverage report that can't be parsed is logged and skipped.
+func (build *Build) loadCoverage() {
+	var err error
+	build.Coverage, err = parseCoverage(&build.raw, build.path)
+	if err != nil {
+		log.Warnf("couldn't parse coverage of build %s: %v", build.path, err)
+	}
+}
+
+// loadIssues parses the static analysis issues of the build. Only the issues of the last build are exported, so they
+// aren't parsed for every build. Issues files that can't be parsed are logged and skipped.
+func (build *Build) loadIssues() {
+	var err error
+	build.Issues, err = parseStaticAnalysis(&build.raw, build.path)
+	if err != nil {
+		log.Warnf("couldn't parse static analysis results of build %s: %v", build.path, err)
+	}
+}
+
+// loadPipelineFailures attributes the failure of a failed pipeline build to its stage and step. The flow graph of a
+// build can be large, so it's only parsed for the builds whose failures are exported. A flow graph that can't be
+// parsed is logged and leaves the build without failures, so the rest of the build is still exported.
+func (build *Build) loadPipelineFailures() {
+	if build.Result != "FAILURE" {
+		return
+	}
+
+	failures, err := parsePipelineFailures(build.path)
+	if err != nil {
+		log.Warnf("couldn't parse flow nodes of build %s: %v", build.path, err)
+		return
+	}
+	build.PipelineFailures = failures
+}
+
 func parseBuild(path string) (Build, error) {
 	var build Build
 
-	if _, err := os.Stat(path); err != nil {
+	path, err := filepath.EvalSymlinks(path)
+	if err != nil {
 		return build, err
 	}
 
 	xmlPath := filepath.Join(path, "build.xml")
 
-	build, err := newBuildFromXML(xmlPath)
+	build, err = newBuildFromXML(xmlPath)
 	if err != nil {
 		return build, err
 	}
@@ -128,9 +219,25 @@ func parseBuild(path string) (Build, error) {
 	return build, nil
 }
 
+const (
+	xml11Header = "<?xml version='1.1' encoding='UTF-8'?>"
+	xml10Header = "<?xml version='1.0' encoding='UTF-8'?>"
+)
+
 // forceXMLVersion is a dirty hack because the Go XML parser does not support XML 1.1 at this point, and newer version of Jenkins do output this.
 func forceXMLVersion(buf []byte) []byte {
 	str := string(buf)
-	ret := strings.Replace(str, "<?xml version='1.1' encoding='UTF-8'?>", "<?xml version='1.0' encoding='UTF-8'?>", 1)
+	ret := strings.Replace(str, xml11Header, xml10Header, 1)
 	return []byte(ret)
 }
+
+// newXMLDecoder returns a streaming decoder for files that are too large to read at once, applying the same hack as forceXMLVersion.
+func newXMLDecoder(r io.Reader) *xml.Decoder {
+	reader := bufio.NewReader(r)
+	header, _ := reader.Peek(len(xml11Header))
+	if string(header) == xml11Header {
+		reader.Discard(len(xml11Header))
+		return xml.NewDecoder(io.MultiReader(strings.NewReader(xml10Header), reader))
+	}
+	return xml.NewDecoder(reader)
+}
@@ -39,6 +39,10 @@ func TestBuildParse(t *testing.T) {
 		t.Errorf("build.Timestamp is %d, expected %d", build.Timestamp, 1548791914210)
 	}
 
+	if build.StartTime != 1548791914216 {
+		t.Errorf("build.StartTime is %d, expected %d", build.StartTime, 1548791914216)
+	}
+
 	if build.Duration != 49 {
 		t.Errorf("build.Duration is %d, expected %d", build.Duration, 49)
 	}
@@ -0,0 +1,104 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// retainedBuilds caches the retained builds of all jobs between collections.
+var retainedBuilds = newBuildCache()
+
+// buildCache keeps the builds it parsed, so a collection only parses the builds that were added or changed since the
+// previous one. Jenkins only rewrites the build.xml of a completed build when it's edited, like when it's kept forever,
+// so a cached build is used as long as its build.xml keeps its modification time and size. Builds that are still
+// running are never cached.
+type buildCache struct {
+	mutex   sync.Mutex
+	entries map[string]*buildCacheEntry
+}
+
+type buildCacheEntry struct {
+	modTime time.Time
+	size    int64
+	build   Build
+	used    time.Time
+}
+
+func newBuildCache() *buildCache {
+	return &buildCache{entries: make(map[string]*buildCacheEntry)}
+}
+
+// get returns the build in the folder at path, with the failures of a failed pipeline loaded. The data of plugins that
+// is only exported for the permalinks can't be loaded from it.
+func (cache *buildCache) get(path string) (Build, error) {
+	path, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return Build{}, err
+	}
+
+	xmlPath := filepath.Join(path, "build.xml")
+	info, err := os.Stat(xmlPath)
+	if err != nil {
+		return Build{}, err
+	}
+
+	cache.mutex.Lock()
+	entry, ok := cache.entries[path]
+	if ok && entry.modTime.Equal(info.ModTime()) && entry.size == info.Size() {
+		entry.used = time.Now()
+		cache.mutex.Unlock()
+		return entry.build, nil
+	}
+	cache.mutex.Unlock()
+
+	build, err := newBuildFromXML(xmlPath)
+	if err != nil {
+		return build, err
+	}
+	build.loadPipelineFailures()
+	// the raw XML takes up most of the memory of a build
+	build.raw = buildXML{}
+
+	if build.Result == "" {
+		return build, nil
+	}
+
+	cache.mutex.Lock()
+	cache.entries[path] = &buildCacheEntry{modTime: info.ModTime(), size: info.Size(), build: build, used: time.Now()}
+	cache.mutex.Unlock()
+
+	return build, nil
+}
+
+// prune drops the builds that weren't used since the given time.
+func (cache *buildCache) prune(since time.Time) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	for path, entry := range cache.entries {
+		if entry.used.Before(since) {
+			delete(cache.entries, path)
+		}
+	}
+}
+
+// PruneBuildCache drops the cached builds that weren't used since the given time, like the builds that were discarded
+// and the builds of jobs that were deleted. Call it after every collection with the time the collection started.
+func PruneBuildCache(since time.Time) {
+	retainedBuilds.prune(since)
+}
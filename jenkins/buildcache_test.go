@@ -0,0 +1,84 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeBuildXML(t *testing.T, dir, result string, modTime time.Time) {
+	content := "<?xml version='1.1' encoding='UTF-8'?>\n<build>\n  <number>1</number>\n  <result>" + result + "</result>\n  <duration>1000</duration>\n</build>\n"
+	path := filepath.Join(dir, "build.xml")
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBuildCache(t *testing.T) {
+	root, err := ioutil.TempDir("", "buildcache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	dir := filepath.Join(root, "1")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cache := newBuildCache()
+	modTime := time.Date(2019, 11, 8, 12, 0, 0, 0, time.UTC)
+
+	// running builds aren't cached
+	writeBuildXML(t, dir, "", modTime)
+	if build, err := cache.get(dir); err != nil || build.Result != "" {
+		t.Fatalf("running build is %+v (%v), expected no result", build, err)
+	}
+	if len(cache.entries) != 0 {
+		t.Errorf("cache holds %d builds, expected the running build not to be cached", len(cache.entries))
+	}
+
+	writeBuildXML(t, dir, "FAILURE", modTime.Add(time.Minute))
+	if build, err := cache.get(dir); err != nil || build.Result != "FAILURE" {
+		t.Fatalf("completed build is %+v (%v), expected a failure", build, err)
+	}
+
+	// a build.xml that is rewritten with the same size and modification time isn't parsed again
+	writeBuildXML(t, dir, "SUCCESS", modTime.Add(time.Minute))
+	if build, _ := cache.get(dir); build.Result != "FAILURE" {
+		t.Errorf("cached build has result %s, expected %s", build.Result, "FAILURE")
+	}
+
+	writeBuildXML(t, dir, "SUCCESS", modTime.Add(2*time.Minute))
+	if build, _ := cache.get(dir); build.Result != "SUCCESS" {
+		t.Errorf("changed build has result %s, expected %s", build.Result, "SUCCESS")
+	}
+
+	cache.prune(time.Now().Add(-time.Hour))
+	if len(cache.entries) != 1 {
+		t.Errorf("cache holds %d builds after pruning unused builds, expected %d", len(cache.entries), 1)
+	}
+
+	cache.prune(time.Now().Add(time.Hour))
+	if len(cache.entries) != 0 {
+		t.Errorf("cache holds %d builds after pruning all builds, expected none", len(cache.entries))
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxChainLength bounds the number of builds TraceChain follows, in case the upstream causes form a cycle.
+const maxChainLength = 100
+
+// Chain is a sequence of builds that triggered each other through their upstream causes, starting with the root build.
+type Chain []ChainBuild
+
+// ChainBuild is a build in a Chain, together with the full name of its job.
+type ChainBuild struct {
+	Job string
+	Build
+}
+
+// Hop is the step from one build in a chain to the build it triggered.
+type Hop struct {
+	Upstream   string
+	Downstream string
+	// Wait is the time between the end of the upstream build and the start of the downstream build. It is negative
+	// when the upstream build waited for the downstream build to finish, like the build step of pipelines does.
+	Wait time.Duration
+}
+
+// TraceChain follows the upstream causes of the build of the job back to the build that started the chain, reading
+// the builds of the upstream jobs from the Jenkins folder at root. The chain stops at the first upstream build that
+// is no longer retained.
+func TraceChain(root string, job Job, build Build) Chain {
+	chain := Chain{{Job: job.FullName(), Build: build}}
+	visited := map[string]bool{}
+
+	for len(chain) < maxChainLength {
+		current := chain[0]
+		if len(current.UpstreamCauses) == 0 {
+			break
+		}
+
+		cause := current.UpstreamCauses[0]
+		key := fmt.Sprintf("%s#%d", cause.Project, cause.Build)
+		if visited[key] {
+			break
+		}
+		visited[key] = true
+
+		path, ok := buildPath(root, cause.Project, cause.Build)
+		if !ok {
+			break
+		}
+		upstream, err := parseBuild(path)
+		if err != nil {
+			break
+		}
+
+		chain = append(Chain{{Job: cause.Project, Build: upstream}}, chain...)
+	}
+
+	return chain
+}
+
+// LeadTime returns the time from the start of the root build to the end of the last build of the chain.
+func (chain Chain) LeadTime() time.Duration {
+	if len(chain) == 0 {
+		return 0
+	}
+	first, last := chain[0], chain[len(chain)-1]
+	return time.Duration(startTime(last.Build)+last.Duration-startTime(first.Build)) * time.Millisecond
+}
+
+// Hops returns the steps between the builds of the chain, starting at the root build.
+func (chain Chain) Hops() []Hop {
+	var hops []Hop
+	for i := 1; i < len(chain); i++ {
+		upstream, downstream := chain[i-1], chain[i]
+		hops = append(hops, Hop{
+			Upstream:   upstream.Job,
+			Downstream: downstream.Job,
+			Wait:       time.Duration(startTime(downstream.Build)-startTime(upstream.Build)-upstream.Duration) * time.Millisecond,
+		})
+	}
+	return hops
+}
+
+// startTime returns the time a build started running. The duration of a build counts from its start time, while its
+// timestamp is the time it was scheduled, which can be a quiet period or longer before it started. Builds that don't
+// record their start time started at their timestamp.
+func startTime(build Build) int {
+	if build.StartTime == 0 {
+		return build.Timestamp
+	}
+	return build.StartTime
+}
+
+// buildPath returns the folder of a build of the job with the given full name, or false when the job doesn't exist.
+func buildPath(root, fullName string, number int) (string, bool) {
+	path, ok := jobDir(root, fullName)
+	if !ok {
+		return "", false
+	}
+	return filepath.Join(path, "builds", strconv.Itoa(number)), true
+}
+
+// jobDir returns the folder of the job with the given full name, resolving every name in it the way FullName builds
+// it. Folders and jobs are stored in the jobs folder of their parent, and sub-jobs in the folder of their kind.
+func jobDir(root, fullName string) (string, bool) {
+	path := root
+	for _, name := range strings.Split(fullName, "/") {
+		dir, ok := childJobDir(path, name)
+		if !ok {
+			return "", false
+		}
+		path = dir
+	}
+	return path, true
+}
+
+// childJobDir returns the folder of the job or sub-job with the given name in the job or folder at path. The folders
+// of branches are mangled, so they are looked up by the name of the branch instead.
+func childJobDir(path, name string) (string, bool) {
+	candidates := []string{
+		filepath.Join(path, "jobs", name),
+		filepath.Join(path, "modules", name),
+	}
+	if strings.Contains(name, "=") {
+		candidates = append(candidates, filepath.Join(path, "configurations", axesPath(name)))
+	}
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			return candidate, true
+		}
+	}
+
+	branchDirs, _ := filepath.Glob(filepath.Join(path, "branches", "*"))
+	for _, branchDir := range branchDirs {
+		if branch, _ := parseBranch(branchDir); url.PathEscape(branch) == name {
+			return branchDir, true
+		}
+	}
+
+	return "", false
+}
+
+// axesPath turns the axes of a matrix configuration (jdk=11,os=linux) into its path (axis-jdk/11/axis-os/linux), the
+// reverse of parseAxes.
+func axesPath(axes string) string {
+	var tokens []string
+	for _, axis := range strings.Split(axes, ",") {
+		parts := strings.SplitN(axis, "=", 2)
+		if len(parts) != 2 {
+			return ""
+		}
+		tokens = append(tokens, "axis-"+parts[0], parts[1])
+	}
+	return filepath.Join(tokens...)
+}
@@ -0,0 +1,125 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTraceChain(t *testing.T) {
+	job, err := JobPath(failedJob).Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chain := TraceChain("testdata", job, job.LastBuild)
+
+	var jobs []string
+	for _, build := range chain {
+		jobs = append(jobs, build.Job)
+	}
+	expected := []string{"rootjob", "folder/folderjob", "folder/failedjob"}
+	if !reflect.DeepEqual(jobs, expected) {
+		t.Fatalf("chain is %v, expected %v", jobs, expected)
+	}
+
+	if leadTime := chain.LeadTime(); leadTime != 1334417*time.Millisecond {
+		t.Errorf("lead time is %v, expected %v", leadTime, 1334417*time.Millisecond)
+	}
+
+	expectedHops := []Hop{
+		{Upstream: "rootjob", Downstream: "folder/folderjob", Wait: 35126 * time.Millisecond},
+		{Upstream: "folder/folderjob", Downstream: "folder/failedjob", Wait: 1299204 * time.Millisecond},
+	}
+	if hops := chain.Hops(); !reflect.DeepEqual(hops, expectedHops) {
+		t.Errorf("hops are %+v, expected %+v", hops, expectedHops)
+	}
+}
+
+func TestTraceChainStopsAtMissingBuild(t *testing.T) {
+	build := Build{Number: 5, Timestamp: 1000, Duration: 500, UpstreamCauses: []UpstreamCause{{Project: "folder/folderjob", Build: 42}}}
+
+	chain := TraceChain("testdata", Job{Folder: "/", Name: "deploy"}, build)
+
+	if len(chain) != 1 || chain[0].Job != "deploy" {
+		t.Errorf("chain is %+v, expected only the deploy build", chain)
+	}
+
+	if chain.Hops() != nil {
+		t.Errorf("hops are %+v, expected none", chain.Hops())
+	}
+
+	if chain.LeadTime() != 500*time.Millisecond {
+		t.Errorf("lead time is %v, expected %v", chain.LeadTime(), 500*time.Millisecond)
+	}
+}
+
+func TestTraceChainBranchUpstream(t *testing.T) {
+	build := Build{Number: 7, Timestamp: 1573230200000, Duration: 1000, UpstreamCauses: []UpstreamCause{{Project: "multibranch/feature%2Flogin", Build: 1}}}
+
+	chain := TraceChain("testdata", Job{Folder: "/", Name: "deploy"}, build)
+
+	var jobs []string
+	for _, build := range chain {
+		jobs = append(jobs, build.Job)
+	}
+	expected := []string{"multibranch/feature%2Flogin", "deploy"}
+	if !reflect.DeepEqual(jobs, expected) {
+		t.Fatalf("chain is %v, expected %v", jobs, expected)
+	}
+
+	if leadTime := chain.LeadTime(); leadTime != 100997*time.Millisecond {
+		t.Errorf("lead time is %v, expected %v", leadTime, 100997*time.Millisecond)
+	}
+}
+
+func TestBuildPath(t *testing.T) {
+	tests := []struct {
+		fullName string
+		expected string
+	}{
+		{"folder/failedjob", "testdata/jobs/folder/jobs/failedjob/builds/3"},
+		{"multibranch/feature%2Flogin", "testdata/jobs/multibranch/branches/feature-login.7ajqb1/builds/3"},
+		{"matrixjob/jdk=11,os=linux", "testdata/jobs/matrixjob/configurations/axis-jdk/11/axis-os/linux/builds/3"},
+		{"mavenjob/com.example$app", "testdata/jobs/mavenjob/modules/com.example$app/builds/3"},
+	}
+
+	for _, test := range tests {
+		path, ok := buildPath("testdata", test.fullName, 3)
+		if !ok || path != filepath.FromSlash(test.expected) {
+			t.Errorf("build path of %s is (%s, %t), expected (%s, true)", test.fullName, path, ok, test.expected)
+		}
+	}
+
+	for _, fullName := range []string{"folder/removedjob", "multibranch/removed", "matrixjob/jdk=8,os=linux"} {
+		if path, ok := buildPath("testdata", fullName, 3); ok {
+			t.Errorf("build path of %s is %s, expected none", fullName, path)
+		}
+	}
+}
+
+func TestHasDownstream(t *testing.T) {
+	graph := dependencyGraph(t, successfulJob, failedJob)
+
+	if !graph.HasDownstream("folder/folderjob") {
+		t.Error("folder/folderjob doesn't have downstream jobs")
+	}
+
+	if graph.HasDownstream("folder/failedjob") {
+		t.Error("folder/failedjob has downstream jobs")
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// jobTypes maps the root element of a config.xml to the type of the job or folder.
+// Unknown root elements are used as the type as is.
+var jobTypes = map[string]string{
+	"project":          "freestyle",
+	"flow-definition":  "pipeline",
+	"matrix-project":   "matrix",
+	"matrix-config":    "matrix-configuration",
+	"maven2-moduleset": "maven",
+	"maven2":           "maven-module",
+	"com.cloudbees.hudson.plugins.folder.Folder":                            "folder",
+	"org.jenkinsci.plugins.workflow.multibranch.WorkflowMultiBranchProject": "multibranch",
+	"jenkins.branch.OrganizationFolder":                                     "organization-folder",
+}
+
+// triggerTypes maps the cron based triggers to the type reported in Trigger.
+var triggerTypes = map[string]string{
+	"hudson.triggers.TimerTrigger": TimerTrigger,
+	"hudson.triggers.SCMTrigger":   SCMTrigger,
+}
+
+// Trigger types reported in Trigger.Type.
+const (
+	TimerTrigger = "timer"
+	SCMTrigger   = "scm"
+)
+
+// JobConfig represents the configuration of a job, as stored in its config.xml.
+type JobConfig struct {
+	raw             jobConfigXML
+	Type            string
+	Description     string
+	Disabled        bool
+	ConcurrentBuild bool
+	AssignedNode    string
+	Triggers        []Trigger
+	BuildDiscarder  *BuildDiscarder
+	SCM             SCMConfig
+	// ChildProjects and UpstreamProjects are the jobs that are built after this one and the jobs whose builds trigger
+	// this one. Their names are relative to the folder of the job, unless they start with a /.
+	ChildProjects    []string
+	UpstreamProjects []string
+	Parameters       []Parameter
+	// ModTime is the time the configuration was last saved.
+	ModTime time.Time
+}
+
+// Trigger is a cron based trigger of a job, its spec can be parsed with ParseCronTab.
+type Trigger struct {
+	Type string
+	Spec string
+}
+
+// BuildDiscarder holds the limits of the build discarder of a job. Limits that aren't set are -1.
+type BuildDiscarder struct {
+	DaysToKeep         int
+	NumToKeep          int
+	ArtifactDaysToKeep int
+	ArtifactNumToKeep  int
+}
+
+// DiscardsBuilds reports whether the discarder removes old builds, and not only their artifacts.
+func (discarder *BuildDiscarder) DiscardsBuilds() bool {
+	return discarder != nil && (discarder.DaysToKeep != -1 || discarder.NumToKeep != -1)
+}
+
+type jobConfigXML struct {
+	XMLName                 xml.Name
+	Description             string                  `xml:"description"`
+	Disabled                bool                    `xml:"disabled"`
+	ConcurrentBuild         bool                    `xml:"concurrentBuild"`
+	AssignedNode            string                  `xml:"assignedNode"`
+	DisableConcurrentBuilds *struct{}               `xml:"properties>org.jenkinsci.plugins.workflow.job.properties.DisableConcurrentBuildsJobProperty"`
+	Triggers                triggersXML             `xml:"triggers"`
+	PipelineTriggers        triggersXML             `xml:"properties>org.jenkinsci.plugins.workflow.job.properties.PipelineTriggersJobProperty>triggers"`
+	BuildDiscarder          *logRotatorXML          `xml:"properties>jenkins.model.BuildDiscarderProperty>strategy"`
+	LogRotator              *logRotatorXML          `xml:"logRotator"`
+	SCM                     *scmXML                 `xml:"scm"`
+	Definition              *definitionXML          `xml:"definition"`
+	BranchSCM               *scmXML                 `xml:"properties>org.jenkinsci.plugins.workflow.multibranch.BranchJobProperty>branch>scm"`
+	Sources                 []scmXML                `xml:"sources>data>jenkins.branch.BranchSource>source"`
+	Navigators              navigatorsXML           `xml:"navigators"`
+	ChildProjects           []string                `xml:"publishers>hudson.tasks.BuildTrigger>childProjects"`
+	Parameters              parameterDefinitionsXML `xml:"properties>hudson.model.ParametersDefinitionProperty>parameterDefinitions"`
+}
+
+type logRotatorXML struct {
+	DaysToKeep         string `xml:"daysToKeep"`
+	NumToKeep          string `xml:"numToKeep"`
+	ArtifactDaysToKeep string `xml:"artifactDaysToKeep"`
+	ArtifactNumToKeep  string `xml:"artifactNumToKeep"`
+}
+
+type triggersXML struct {
+	Triggers []triggerXML `xml:",any"`
+}
+
+type triggerXML struct {
+	XMLName          xml.Name
+	Spec             string `xml:"spec"`
+	UpstreamProjects string `xml:"upstreamProjects"`
+}
+
+func parseJobConfig(path string) (JobConfig, error) {
+	var config JobConfig
+
+	configPath := filepath.Join(path, "config.xml")
+	info, err := os.Stat(configPath)
+	if err != nil {
+		return config, err
+	}
+	config.ModTime = info.ModTime()
+
+	byteValue, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return config, err
+	}
+
+	err = xml.Unmarshal(forceXMLVersion(byteValue), &config.raw)
+	if err != nil {
+		return config, err
+	}
+
+	config.Type = config.raw.XMLName.Local
+	if t, ok := jobTypes[config.Type]; ok {
+		config.Type = t
+	}
+	config.Description = config.raw.Description
+	config.Disabled = config.raw.Disabled
+	config.AssignedNode = config.raw.AssignedNode
+
+	// Pipelines don't store concurrentBuild, they allow concurrent builds unless the job property disables them
+	if config.raw.XMLName.Local == "flow-definition" {
+		config.ConcurrentBuild = config.raw.DisableConcurrentBuilds == nil
+	} else {
+		config.ConcurrentBuild = config.raw.ConcurrentBuild
+	}
+
+	// Pipelines keep their triggers in a job property
+	for _, trigger := range append(config.raw.Triggers.Triggers, config.raw.PipelineTriggers.Triggers...) {
+		if trigger.XMLName.Local == "jenkins.triggers.ReverseBuildTrigger" {
+			config.UpstreamProjects = append(config.UpstreamProjects, splitProjectNames(trigger.UpstreamProjects)...)
+			continue
+		}
+
+		t, ok := triggerTypes[trigger.XMLName.Local]
+		if !ok || strings.TrimSpace(trigger.Spec) == "" {
+			continue
+		}
+		config.Triggers = append(config.Triggers, Trigger{Type: t, Spec: trigger.Spec})
+	}
+
+	config.SCM = parseSCM(&config.raw)
+	config.Parameters = config.raw.Parameters.Parameters
+
+	for _, childProjects := range config.raw.ChildProjects {
+		config.ChildProjects = append(config.ChildProjects, splitProjectNames(childProjects)...)
+	}
+
+	// Jobs created before Jenkins 1.637 still have their log rotator at the top level
+	if rotator := config.raw.BuildDiscarder; rotator != nil || config.raw.LogRotator != nil {
+		if rotator == nil {
+			rotator = config.raw.LogRotator
+		}
+		config.BuildDiscarder = &BuildDiscarder{
+			DaysToKeep:         discarderLimit(rotator.DaysToKeep),
+			NumToKeep:          discarderLimit(rotator.NumToKeep),
+			ArtifactDaysToKeep: discarderLimit(rotator.ArtifactDaysToKeep),
+			ArtifactNumToKeep:  discarderLimit(rotator.ArtifactNumToKeep),
+		}
+	}
+
+	return config, nil
+}
+
+// splitProjectNames splits the comma separated list of job names Jenkins uses in build triggers.
+func splitProjectNames(names string) []string {
+	var projects []string
+	for _, name := range strings.Split(names, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			projects = append(projects, name)
+		}
+	}
+	return projects
+}
+
+// discarderLimit parses a limit of the log rotator. Jenkins ignores limits that are empty or not positive.
+func discarderLimit(value string) int {
+	limit, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || limit <= 0 {
+		return -1
+	}
+	return limit
+}
@@ -0,0 +1,131 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestJobConfig(t *testing.T) {
+	config, err := parseJobConfig(rootfolderJob)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Type != "freestyle" {
+		t.Errorf("config.Type is %s, expected %s", config.Type, "freestyle")
+	}
+
+	if config.AssignedNode != "linux" {
+		t.Errorf("config.AssignedNode is %s, expected %s", config.AssignedNode, "linux")
+	}
+
+	if config.Disabled {
+		t.Error("config.Disabled is true, expected false")
+	}
+
+	if config.ConcurrentBuild {
+		t.Error("config.ConcurrentBuild is true, expected false")
+	}
+}
+
+func TestPipelineJobConfig(t *testing.T) {
+	config, err := parseJobConfig(pipelineJob)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Type != "pipeline" {
+		t.Errorf("config.Type is %s, expected %s", config.Type, "pipeline")
+	}
+
+	if !config.ConcurrentBuild {
+		t.Error("config.ConcurrentBuild is false, expected pipelines to allow concurrent builds by default")
+	}
+}
+
+func TestJobConfigTypes(t *testing.T) {
+	tests := map[string]string{
+		matrixJob:                    "matrix",
+		matrixConfig:                 "matrix-configuration",
+		"testdata/jobs/mavenjob":     "maven",
+		mavenModule:                  "maven-module",
+		folder:                       "folder",
+		"testdata/jobs/multibranch":  "multibranch",
+		"testdata/jobs/organization": "organization-folder",
+	}
+
+	for path, expected := range tests {
+		config, err := parseJobConfig(path)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if config.Type != expected {
+			t.Errorf("type of %s is %s, expected %s", path, config.Type, expected)
+		}
+	}
+}
+
+func TestDisabledJobWithoutBuilds(t *testing.T) {
+	job := Job{
+		path: JobPath(jobWithoutBuilds),
+	}
+
+	job.fetch()
+
+	if job.Name != "jobwithoutbuilds" {
+		t.Errorf("job.Name is %s, expected %s", job.Name, "jobwithoutbuilds")
+	}
+
+	if !job.Config.Disabled {
+		t.Error("job.Config.Disabled is false, expected true")
+	}
+}
+
+func TestJobConfigTriggers(t *testing.T) {
+	config, err := parseJobConfig(rootfolderJob)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Trigger{{Type: TimerTrigger, Spec: "H 2 * * *"}}
+	if !reflect.DeepEqual(config.Triggers, expected) {
+		t.Errorf("config.Triggers is %v, expected %v", config.Triggers, expected)
+	}
+}
+
+func TestPipelineJobConfigTriggers(t *testing.T) {
+	config, err := parseJobConfig(pipelineJob)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(config.Triggers) != 2 {
+		t.Fatalf("config.Triggers has %d triggers, expected 2", len(config.Triggers))
+	}
+
+	if config.Triggers[0].Type != SCMTrigger || config.Triggers[0].Spec != "H/15 * * * *" {
+		t.Errorf("config.Triggers[0] is %v, expected the H/15 * * * * scm trigger", config.Triggers[0])
+	}
+
+	if config.Triggers[1].Type != TimerTrigger {
+		t.Errorf("config.Triggers[1].Type is %s, expected %s", config.Triggers[1].Type, TimerTrigger)
+	}
+
+	if _, err := ParseCronTab(config.Triggers[1].Spec, "folder/pipelinejob"); err != nil {
+		t.Errorf("parsing the timer trigger failed: %v", err)
+	}
+}
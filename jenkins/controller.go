@@ -0,0 +1,97 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// Default locations of builds and workspaces, used when they aren't configured in the config.xml of the controller.
+const (
+	DefaultBuildsDir    = "${ITEM_ROOTDIR}/builds"
+	DefaultWorkspaceDir = "${JENKINS_HOME}/workspace/${ITEM_FULL_NAME}"
+)
+
+// defaultExecutors is the number of executors of the controller when numExecutors isn't configured.
+const defaultExecutors = 2
+
+// Controller represents the global configuration of the Jenkins controller, as stored in JENKINS_HOME/config.xml.
+type Controller struct {
+	raw                   controllerXML
+	Version               string
+	NumExecutors          int
+	Mode                  string
+	Label                 string
+	UseSecurity           bool
+	SecurityRealm         string
+	AuthorizationStrategy string
+	BuildsDir             string
+	WorkspaceDir          string
+}
+
+type controllerXML struct {
+	Version               string   `xml:"version"`
+	NumExecutors          *int     `xml:"numExecutors"`
+	Mode                  string   `xml:"mode"`
+	Label                 string   `xml:"label"`
+	UseSecurity           bool     `xml:"useSecurity"`
+	SecurityRealm         classXML `xml:"securityRealm"`
+	AuthorizationStrategy classXML `xml:"authorizationStrategy"`
+	BuildsDir             string   `xml:"buildsDir"`
+	WorkspaceDir          string   `xml:"workspaceDir"`
+}
+
+type classXML struct {
+	Class string `xml:"class,attr"`
+}
+
+// ParseController parses the global configuration of the Jenkins controller in the Jenkins folder at root.
+func ParseController(root string) (Controller, error) {
+	var controller Controller
+
+	byteValue, err := ioutil.ReadFile(filepath.Join(root, "config.xml"))
+	if err != nil {
+		return controller, err
+	}
+
+	err = xml.Unmarshal(forceXMLVersion(byteValue), &controller.raw)
+	if err != nil {
+		return controller, err
+	}
+
+	controller.Version = controller.raw.Version
+	controller.NumExecutors = defaultExecutors
+	if controller.raw.NumExecutors != nil {
+		controller.NumExecutors = *controller.raw.NumExecutors
+	}
+	controller.Mode = controller.raw.Mode
+	controller.Label = controller.raw.Label
+	controller.UseSecurity = controller.raw.UseSecurity
+	controller.SecurityRealm = controller.raw.SecurityRealm.Class
+	controller.AuthorizationStrategy = controller.raw.AuthorizationStrategy.Class
+
+	controller.BuildsDir = strings.TrimSpace(controller.raw.BuildsDir)
+	if controller.BuildsDir == "" {
+		controller.BuildsDir = DefaultBuildsDir
+	}
+	controller.WorkspaceDir = strings.TrimSpace(controller.raw.WorkspaceDir)
+	if controller.WorkspaceDir == "" {
+		controller.WorkspaceDir = DefaultWorkspaceDir
+	}
+
+	return controller, nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"testing"
+)
+
+func TestParseController(t *testing.T) {
+	controller, err := ParseController("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"version":                "2.204.1",
+		"mode":                   "EXCLUSIVE",
+		"label":                  "master",
+		"security realm":         "hudson.security.HudsonPrivateSecurityRealm",
+		"authorization strategy": "hudson.security.FullControlOnceLoggedInAuthorizationStrategy",
+		"builds dir":             DefaultBuildsDir,
+		"workspace dir":          DefaultWorkspaceDir,
+	}
+	actual := map[string]string{
+		"version":                controller.Version,
+		"mode":                   controller.Mode,
+		"label":                  controller.Label,
+		"security realm":         controller.SecurityRealm,
+		"authorization strategy": controller.AuthorizationStrategy,
+		"builds dir":             controller.BuildsDir,
+		"workspace dir":          controller.WorkspaceDir,
+	}
+	for field, value := range expected {
+		if actual[field] != value {
+			t.Errorf("%s is %s, expected %s", field, actual[field], value)
+		}
+	}
+
+	if controller.NumExecutors != 2 {
+		t.Errorf("number of executors is %d, expected %d", controller.NumExecutors, 2)
+	}
+
+	if !controller.UseSecurity {
+		t.Error("security is disabled, expected it to be enabled")
+	}
+}
+
+func TestParseControllerMissingConfig(t *testing.T) {
+	_, err := ParseController("testdata/jobs")
+	if err == nil {
+		t.Error("expected an error for a folder without config.xml")
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Coverage types reported in Build.Coverage.
+const (
+	LineCoverage   = "line"
+	BranchCoverage = "branch"
+)
+
+type jacocoBuildActionXML struct {
+	Line   jacocoCounterXML `xml:"line"`
+	Branch jacocoCounterXML `xml:"branch"`
+}
+
+type jacocoCounterXML struct {
+	Missed      int  `xml:"missed"`
+	Covered     int  `xml:"covered"`
+	Initialized bool `xml:"initialized"`
+}
+
+type coverageBuildActionXML struct {
+	LineCoverage   string `xml:"lineCoverage"`
+	BranchCoverage string `xml:"branchCoverage"`
+}
+
+func (counter jacocoCounterXML) ratio() (float64, bool) {
+	total := counter.Missed + counter.Covered
+	if !counter.Initialized || total == 0 {
+		return 0, false
+	}
+	return float64(counter.Covered) / float64(total), true
+}
+
+// parseCoverage collects the coverage ratios of a build. Cobertura leaves its report in coverage.xml, JaCoCo and the
+// Code Coverage API plugin record their totals as an action in build.xml. The first source reporting a type wins. The
+// ratios of build.xml are still returned when the Cobertura report can't be parsed.
+func parseCoverage(build *buildXML, buildPath string) (map[string]float64, error) {
+	coverage := make(map[string]float64)
+
+	cobertura, err := parseCoberturaReport(filepath.Join(buildPath, "coverage.xml"))
+	if os.IsNotExist(err) {
+		err = nil
+	}
+	for t, ratio := range cobertura {
+		coverage[t] = ratio
+	}
+
+	if ratio, ok := build.Actions.Jacoco.Line.ratio(); ok {
+		setCoverage(coverage, LineCoverage, ratio)
+	}
+	if ratio, ok := build.Actions.Jacoco.Branch.ratio(); ok {
+		setCoverage(coverage, BranchCoverage, ratio)
+	}
+
+	if ratio, err := parseCoverageFraction(build.Actions.CodeCoverage.LineCoverage); err == nil {
+		setCoverage(coverage, LineCoverage, ratio)
+	}
+	if ratio, err := parseCoverageFraction(build.Actions.CodeCoverage.BranchCoverage); err == nil {
+		setCoverage(coverage, BranchCoverage, ratio)
+	}
+
+	return coverage, err
+}
+
+func setCoverage(coverage map[string]float64, t string, ratio float64) {
+	if _, ok := coverage[t]; !ok {
+		coverage[t] = ratio
+	}
+}
+
+// parseCoberturaReport only reads the root element of the report, since the totals are stored as attributes there
+// and the rest of the file can be huge.
+func parseCoberturaReport(path string) (map[string]float64, error) {
+	coverage := make(map[string]float64)
+
+	file, err := os.Open(path)
+	if err != nil {
+		return coverage, err
+	}
+	defer file.Close()
+
+	decoder := newXMLDecoder(file)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return coverage, fmt.Errorf("no coverage element found in %s", path)
+		}
+		if err != nil {
+			return coverage, err
+		}
+
+		element, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if element.Name.Local != "coverage" {
+			return coverage, fmt.Errorf("unexpected root element %s in %s", element.Name.Local, path)
+		}
+
+		for _, attr := range element.Attr {
+			ratio, err := strconv.ParseFloat(attr.Value, 64)
+			if err != nil {
+				continue
+			}
+			switch attr.Name.Local {
+			case "line-rate":
+				coverage[LineCoverage] = ratio
+			case "branch-rate":
+				coverage[BranchCoverage] = ratio
+			}
+		}
+		return coverage, nil
+	}
+}
+
+// parseCoverageFraction parses the covered/total notation the Code Coverage API plugin uses to store its totals.
+func parseCoverageFraction(value string) (float64, error) {
+	tokens := strings.Split(strings.TrimSpace(value), "/")
+	if len(tokens) != 2 {
+		return 0, fmt.Errorf("unexpected coverage format %q", value)
+	}
+
+	covered, err := strconv.Atoi(tokens[0])
+	if err != nil {
+		return 0, err
+	}
+	total, err := strconv.Atoi(tokens[1])
+	if err != nil {
+		return 0, err
+	}
+	if total == 0 {
+		return 0, fmt.Errorf("no coverage recorded in %q", value)
+	}
+
+	return float64(covered) / float64(total), nil
+}
@@ -0,0 +1,92 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import "testing"
+
+func TestCoberturaCoverage(t *testing.T) {
+	build, err := parseBuild(pipelineJob + "/builds/lastSuccessfulBuild")
+	if err != nil {
+		t.Fatal(err)
+	}
+	build.loadCoverage()
+
+	if build.Coverage[LineCoverage] != 0.8125 {
+		t.Errorf("build.Coverage[line] is %f, expected %f", build.Coverage[LineCoverage], 0.8125)
+	}
+
+	if build.Coverage[BranchCoverage] != 0.625 {
+		t.Errorf("build.Coverage[branch] is %f, expected %f", build.Coverage[BranchCoverage], 0.625)
+	}
+}
+
+func TestJacocoCoverage(t *testing.T) {
+	build, err := parseBuild(successfulJob + "/builds/lastSuccessfulBuild")
+	if err != nil {
+		t.Fatal(err)
+	}
+	build.loadCoverage()
+
+	if build.Coverage[LineCoverage] != 0.8 {
+		t.Errorf("build.Coverage[line] is %f, expected %f", build.Coverage[LineCoverage], 0.8)
+	}
+
+	if build.Coverage[BranchCoverage] != 0.75 {
+		t.Errorf("build.Coverage[branch] is %f, expected %f", build.Coverage[BranchCoverage], 0.75)
+	}
+}
+
+func TestNoCoverage(t *testing.T) {
+	build, err := parseBuild(testbuild)
+	if err != nil {
+		t.Fatal(err)
+	}
+	build.loadCoverage()
+
+	if len(build.Coverage) != 0 {
+		t.Errorf("build.Coverage is %v, expected no coverage", build.Coverage)
+	}
+}
+
+func TestParseCoverageFraction(t *testing.T) {
+	ratio, err := parseCoverageFraction("9/20")
+	if err != nil {
+		t.Error(err)
+	}
+	if ratio != 0.45 {
+		t.Errorf("ratio is %f, expected %f", ratio, 0.45)
+	}
+
+	for _, value := range []string{"", "0/0", "nine/twenty", "45%"} {
+		if _, err := parseCoverageFraction(value); err == nil {
+			t.Errorf("parsing %q should return an error", value)
+		}
+	}
+}
+
+func TestMalformedCoverageReport(t *testing.T) {
+	build, err := parseBuild(malformedBuild)
+	if err != nil {
+		t.Fatal(err)
+	}
+	build.loadCoverage()
+
+	if build.Coverage[LineCoverage] != 0.5 {
+		t.Errorf("build.Coverage[line] is %f, expected %f", build.Coverage[LineCoverage], 0.5)
+	}
+
+	if _, ok := build.Coverage[BranchCoverage]; ok {
+		t.Errorf("build.Coverage[branch] is %f, expected no coverage", build.Coverage[BranchCoverage])
+	}
+}
@@ -0,0 +1,353 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"crypto/md5"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	cronMinute = iota
+	cronHour
+	cronDayOfMonth
+	cronMonth
+	cronDayOfWeek
+)
+
+// maxCronIterations bounds the search for the next or previous run, so specs that never fire (e.g. H H 30 2 *) end.
+const maxCronIterations = 100000
+
+var (
+	cronLowerBounds = [5]int{0, 0, 1, 1, 0}
+	cronUpperBounds = [5]int{59, 23, 31, 12, 7}
+	// cronHashUpperBounds limits H to values that are valid in every month and counts Sunday only once.
+	cronHashUpperBounds = [5]int{59, 23, 28, 12, 6}
+)
+
+var cronAliases = map[string]string{
+	"yearly":   "H H H H *",
+	"annually": "H H H H *",
+	"monthly":  "H H H * *",
+	"weekly":   "H H * * H",
+	"daily":    "H H * * *",
+	"midnight": "H H(0-2) * * *",
+	"hourly":   "H * * * *",
+}
+
+// CronTab is a schedule in the cron syntax used by Jenkins triggers. It can hold multiple lines, and fires whenever
+// one of them matches. Unlike classic cron, a line only matches when both its day of month and day of week match.
+type CronTab struct {
+	entries []cronEntry
+}
+
+type cronEntry struct {
+	bits     [5]uint64
+	location *time.Location
+}
+
+// ParseCronTab parses a cron spec the way Jenkins does. Every H is replaced by a value derived from the hash of the
+// seed, which Jenkins sets to the full name of the job, so the same job always gets the same schedule.
+func ParseCronTab(spec, seed string) (*CronTab, error) {
+	tab := &CronTab{}
+	hash := newCronHash(seed)
+	location := time.Local
+
+	for _, line := range strings.Split(spec, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "TZ=") {
+			var err error
+			location, err = time.LoadLocation(strings.TrimPrefix(line, "TZ="))
+			if err != nil {
+				return nil, fmt.Errorf("invalid time zone in %q: %v", line, err)
+			}
+			continue
+		}
+
+		if strings.HasPrefix(line, "@") {
+			alias, ok := cronAliases[line[1:]]
+			if !ok {
+				return nil, fmt.Errorf("unknown alias %q", line)
+			}
+			line = alias
+		}
+
+		entry, err := parseCronLine(line, hash)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron spec %q: %v", line, err)
+		}
+		entry.location = location
+		tab.entries = append(tab.entries, entry)
+	}
+
+	if len(tab.entries) == 0 {
+		return nil, fmt.Errorf("empty cron spec")
+	}
+
+	return tab, nil
+}
+
+func parseCronLine(line string, hash *javaRandom) (cronEntry, error) {
+	var entry cronEntry
+
+	fields := strings.Fields(line)
+	if len(fields) != 5 {
+		return entry, fmt.Errorf("expected 5 fields, got %d", len(fields))
+	}
+
+	for field, expr := range fields {
+		for _, term := range strings.Split(expr, ",") {
+			bits, err := parseCronTerm(term, field, hash)
+			if err != nil {
+				return entry, err
+			}
+			entry.bits[field] |= bits
+		}
+	}
+
+	// Both 0 and 7 are Sunday
+	if entry.bits[cronDayOfWeek]&(1<<7) != 0 {
+		entry.bits[cronDayOfWeek] = entry.bits[cronDayOfWeek]&^(1<<7) | 1
+	}
+
+	return entry, nil
+}
+
+func parseCronTerm(term string, field int, hash *javaRandom) (uint64, error) {
+	step := 0
+	if i := strings.Index(term, "/"); i != -1 {
+		var err error
+		step, err = strconv.Atoi(term[i+1:])
+		if err != nil || step <= 0 {
+			return 0, fmt.Errorf("invalid step in %q", term)
+		}
+		term = term[:i]
+	}
+
+	lower, upper := cronLowerBounds[field], cronUpperBounds[field]
+
+	switch {
+	case term == "*":
+		return cronRange(lower, upper, step)
+	case term == "H":
+		return cronHash(lower, cronHashUpperBounds[field], step, hash)
+	case strings.HasPrefix(term, "H(") && strings.HasSuffix(term, ")"):
+		start, end, err := parseCronBounds(term[2:len(term)-1], field)
+		if err != nil {
+			return 0, err
+		}
+		return cronHash(start, end, step, hash)
+	case strings.Contains(term, "-"):
+		start, end, err := parseCronBounds(term, field)
+		if err != nil {
+			return 0, err
+		}
+		return cronRange(start, end, step)
+	}
+
+	if step != 0 {
+		return 0, fmt.Errorf("a step requires a range in %q", term)
+	}
+	value, err := parseCronValue(term, field)
+	if err != nil {
+		return 0, err
+	}
+	return 1 << uint(value), nil
+}
+
+func parseCronBounds(bounds string, field int) (int, int, error) {
+	tokens := strings.Split(bounds, "-")
+	if len(tokens) != 2 {
+		return 0, 0, fmt.Errorf("invalid range %q", bounds)
+	}
+
+	start, err := parseCronValue(tokens[0], field)
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := parseCronValue(tokens[1], field)
+	if err != nil {
+		return 0, 0, err
+	}
+	if start > end {
+		return 0, 0, fmt.Errorf("invalid range %q", bounds)
+	}
+
+	return start, end, nil
+}
+
+func parseCronValue(value string, field int) (int, error) {
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", value)
+	}
+	if v < cronLowerBounds[field] || v > cronUpperBounds[field] {
+		return 0, fmt.Errorf("%d is out of range [%d,%d]", v, cronLowerBounds[field], cronUpperBounds[field])
+	}
+	return v, nil
+}
+
+func cronRange(start, end, step int) (uint64, error) {
+	if step == 0 {
+		step = 1
+	}
+
+	var bits uint64
+	for i := start; i <= end; i += step {
+		bits |= 1 << uint(i)
+	}
+	return bits, nil
+}
+
+// cronHash picks a single value in [start,end] for H, or a hashed offset in [start,start+step) for H/step. Like in
+// Jenkins, H/1 is the same as H.
+func cronHash(start, end, step int, hash *javaRandom) (uint64, error) {
+	if step <= 1 {
+		return 1 << uint(start+int(hash.nextInt(int32(end+1-start)))), nil
+	}
+
+	if step > end-start+1 {
+		return 0, fmt.Errorf("step %d is larger than the range [%d,%d]", step, start, end)
+	}
+
+	var bits uint64
+	for i := int(hash.nextInt(int32(step))) + start; i <= end; i += step {
+		bits |= 1 << uint(i)
+	}
+	return bits, nil
+}
+
+// next returns the first time strictly after t that matches the entry.
+func (entry cronEntry) next(t time.Time) (time.Time, bool) {
+	t = t.In(entry.location).Truncate(time.Minute).Add(time.Minute)
+
+	for i := 0; i < maxCronIterations; i++ {
+		y, m, d := t.Date()
+		switch {
+		case entry.bits[cronMonth]&(1<<uint(m)) == 0:
+			t = time.Date(y, m+1, 1, 0, 0, 0, 0, entry.location)
+		case entry.bits[cronDayOfMonth]&(1<<uint(d)) == 0 || entry.bits[cronDayOfWeek]&(1<<uint(t.Weekday())) == 0:
+			t = time.Date(y, m, d+1, 0, 0, 0, 0, entry.location)
+		case entry.bits[cronHour]&(1<<uint(t.Hour())) == 0:
+			t = time.Date(y, m, d, t.Hour()+1, 0, 0, 0, entry.location)
+		case entry.bits[cronMinute]&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t, true
+		}
+	}
+
+	return t, false
+}
+
+// previous returns the last time at or before t that matches the entry.
+func (entry cronEntry) previous(t time.Time) (time.Time, bool) {
+	t = t.In(entry.location).Truncate(time.Minute)
+
+	for i := 0; i < maxCronIterations; i++ {
+		y, m, d := t.Date()
+		switch {
+		case entry.bits[cronMonth]&(1<<uint(m)) == 0:
+			t = time.Date(y, m, 1, 0, 0, 0, 0, entry.location).Add(-time.Minute)
+		case entry.bits[cronDayOfMonth]&(1<<uint(d)) == 0 || entry.bits[cronDayOfWeek]&(1<<uint(t.Weekday())) == 0:
+			t = time.Date(y, m, d, 0, 0, 0, 0, entry.location).Add(-time.Minute)
+		case entry.bits[cronHour]&(1<<uint(t.Hour())) == 0:
+			t = time.Date(y, m, d, t.Hour(), 0, 0, 0, entry.location).Add(-time.Minute)
+		case entry.bits[cronMinute]&(1<<uint(t.Minute())) == 0:
+			t = t.Add(-time.Minute)
+		default:
+			return t, true
+		}
+	}
+
+	return t, false
+}
+
+// Next returns the first time strictly after t at which the schedule fires.
+func (tab *CronTab) Next(t time.Time) (time.Time, bool) {
+	var next time.Time
+	found := false
+
+	for _, entry := range tab.entries {
+		n, ok := entry.next(t)
+		if ok && (!found || n.Before(next)) {
+			next, found = n, true
+		}
+	}
+
+	return next, found
+}
+
+// Previous returns the last time at or before t at which the schedule fired.
+func (tab *CronTab) Previous(t time.Time) (time.Time, bool) {
+	var previous time.Time
+	found := false
+
+	for _, entry := range tab.entries {
+		p, ok := entry.previous(t)
+		if ok && (!found || p.After(previous)) {
+			previous, found = p, true
+		}
+	}
+
+	return previous, found
+}
+
+// javaRandom reproduces java.util.Random, which Jenkins uses to resolve H.
+type javaRandom struct {
+	seed int64
+}
+
+const javaRandomMultiplier = 0x5DEECE66D
+
+// newCronHash seeds the random generator the way hudson.scheduler.Hash does, from the folded MD5 of the seed.
+func newCronHash(seed string) *javaRandom {
+	digest := md5.Sum([]byte(seed))
+	for i := 8; i < len(digest); i++ {
+		digest[i%8] ^= digest[i]
+	}
+
+	var l uint64
+	for i := 0; i < 8; i++ {
+		l = l<<8 + uint64(digest[i])
+	}
+
+	return &javaRandom{seed: (int64(l) ^ javaRandomMultiplier) & (1<<48 - 1)}
+}
+
+func (r *javaRandom) next(bits uint) int32 {
+	r.seed = (r.seed*javaRandomMultiplier + 0xB) & (1<<48 - 1)
+	return int32(r.seed >> (48 - bits))
+}
+
+func (r *javaRandom) nextInt(bound int32) int32 {
+	if bound&-bound == bound {
+		return int32((int64(bound) * int64(r.next(31))) >> 31)
+	}
+
+	for {
+		bits := r.next(31)
+		val := bits % bound
+		if bits-val+(bound-1) >= 0 {
+			return val
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"testing"
+	"time"
+)
+
+// cronBase is the time the hashed schedules of Jenkins' own CronTabTest start from.
+var cronBase = time.Date(2013, time.March, 21, 16, 21, 0, 0, time.UTC)
+
+func mustParseCronTab(t *testing.T, spec, seed string) *CronTab {
+	t.Helper()
+	tab, err := ParseCronTab("TZ=UTC\n"+spec, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tab
+}
+
+func TestCronTabNext(t *testing.T) {
+	tests := []struct {
+		spec     string
+		seed     string
+		expected time.Time
+	}{
+		{"H 17 * * *", "stuff", time.Date(2013, time.March, 21, 17, 56, 0, 0, time.UTC)},
+		{"H * * * *", "stuff", time.Date(2013, time.March, 21, 16, 56, 0, 0, time.UTC)},
+		{"@hourly", "stuff", time.Date(2013, time.March, 21, 16, 56, 0, 0, time.UTC)},
+		{"@hourly", "junk", time.Date(2013, time.March, 21, 17, 20, 0, 0, time.UTC)},
+		{"@midnight", "stuff", time.Date(2013, time.March, 22, 0, 56, 0, 0, time.UTC)},
+		{"H/1 * * * *", "stuff", time.Date(2013, time.March, 21, 16, 56, 0, 0, time.UTC)},
+		{"H H(12-13) * * *", "stuff", time.Date(2013, time.March, 22, 13, 56, 0, 0, time.UTC)},
+		{"*/15 * * * *", "stuff", time.Date(2013, time.March, 21, 16, 30, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", "stuff", time.Date(2013, time.March, 22, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * 7", "stuff", time.Date(2013, time.March, 24, 9, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", "stuff", time.Date(2013, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * 1", "stuff", time.Date(2013, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 * * *\n30 16 * * *", "stuff", time.Date(2013, time.March, 21, 16, 30, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		next, ok := mustParseCronTab(t, test.spec, test.seed).Next(cronBase)
+		if !ok {
+			t.Errorf("%q never fires", test.spec)
+			continue
+		}
+		if !next.Equal(test.expected) {
+			t.Errorf("next run of %q is %v, expected %v", test.spec, next, test.expected)
+		}
+	}
+}
+
+func TestCronTabPrevious(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected time.Time
+	}{
+		{"21 16 * * *", time.Date(2013, time.March, 21, 16, 21, 0, 0, time.UTC)},
+		{"H 17 * * *", time.Date(2013, time.March, 20, 17, 56, 0, 0, time.UTC)},
+		{"0 0 * * 0", time.Date(2013, time.March, 17, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 1 *", time.Date(2013, time.January, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		previous, ok := mustParseCronTab(t, test.spec, "stuff").Previous(cronBase)
+		if !ok {
+			t.Errorf("%q never fired", test.spec)
+			continue
+		}
+		if !previous.Equal(test.expected) {
+			t.Errorf("previous run of %q is %v, expected %v", test.spec, previous, test.expected)
+		}
+	}
+}
+
+func TestCronTabTimeZone(t *testing.T) {
+	tab, err := ParseCronTab("TZ=Europe/Brussels\n0 2 * * *", "stuff")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next, _ := tab.Next(cronBase)
+	expected := time.Date(2013, time.March, 22, 1, 0, 0, 0, time.UTC)
+	if !next.Equal(expected) {
+		t.Errorf("next run is %v, expected %v", next, expected)
+	}
+}
+
+func TestCronTabNeverFires(t *testing.T) {
+	tab := mustParseCronTab(t, "0 0 30 2 *", "stuff")
+
+	if _, ok := tab.Next(cronBase); ok {
+		t.Error("a schedule on February 30 fires")
+	}
+}
+
+func TestInvalidCronTab(t *testing.T) {
+	specs := []string{
+		"",
+		"# only a comment",
+		"* * * *",
+		"60 * * * *",
+		"* * 0 * *",
+		"5-1 * * * *",
+		"H/0 * * * *",
+		"1/5 * * * *",
+		"@fortnightly",
+		"TZ=Nowhere/Special\n* * * * *",
+	}
+
+	for _, spec := range specs {
+		if _, err := ParseCronTab(spec, "stuff"); err == nil {
+			t.Errorf("parsing %q succeeded, expected an error", spec)
+		}
+	}
+}
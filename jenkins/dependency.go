@@ -0,0 +1,189 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Sources of a Dependency.
+const (
+	// ConfigDependency is declared by a BuildTrigger or ReverseBuildTrigger in the configuration of a job.
+	ConfigDependency = "config"
+	// BuildDependency is recorded by the UpstreamCause of a retained build.
+	BuildDependency = "build"
+)
+
+// Dependency is an edge in the DependencyGraph: builds of Upstream trigger builds of Downstream. Jobs are identified by
+// their full name.
+type Dependency struct {
+	Upstream   string   `json:"upstream"`
+	Downstream string   `json:"downstream"`
+	Sources    []string `json:"sources"`
+}
+
+// DependencyGraph holds the upstream and downstream relations between jobs.
+type DependencyGraph struct {
+	dependencies map[[2]string]*Dependency
+}
+
+// NewDependencyGraph creates an instance of DependencyGraph.
+func NewDependencyGraph() *DependencyGraph {
+	return &DependencyGraph{
+		dependencies: make(map[[2]string]*Dependency),
+	}
+}
+
+// Add adds the dependencies declared in the configuration of the job and recorded by its builds to the graph.
+// Sub-jobs are always triggered by their parent job, so that dependency is left out.
+func (graph *DependencyGraph) Add(job Job) {
+	name := job.FullName()
+	parent := job.parentFullName()
+
+	for _, child := range job.Config.ChildProjects {
+		graph.add(name, resolveJobName(job.Folder, child), ConfigDependency)
+	}
+
+	for _, upstream := range job.Config.UpstreamProjects {
+		graph.add(resolveJobName(job.Folder, upstream), name, ConfigDependency)
+	}
+
+	for _, build := range job.Builds {
+		for _, cause := range build.UpstreamCauses {
+			if cause.Project == parent && parent != name {
+				continue
+			}
+			graph.add(cause.Project, name, BuildDependency)
+		}
+	}
+}
+
+func (graph *DependencyGraph) add(upstream, downstream, source string) {
+	key := [2]string{upstream, downstream}
+
+	dependency, ok := graph.dependencies[key]
+	if !ok {
+		dependency = &Dependency{Upstream: upstream, Downstream: downstream}
+		graph.dependencies[key] = dependency
+	}
+
+	for _, s := range dependency.Sources {
+		if s == source {
+			return
+		}
+	}
+	dependency.Sources = append(dependency.Sources, source)
+	sort.Strings(dependency.Sources)
+}
+
+// HasDownstream reports whether builds of the job trigger builds of other jobs.
+func (graph *DependencyGraph) HasDownstream(job string) bool {
+	for key := range graph.dependencies {
+		if key[0] == job {
+			return true
+		}
+	}
+	return false
+}
+
+// Dependencies returns all dependencies in the graph, ordered by upstream and downstream job.
+func (graph *DependencyGraph) Dependencies() []Dependency {
+	dependencies := []Dependency{}
+	for _, dependency := range graph.dependencies {
+		dependencies = append(dependencies, *dependency)
+	}
+
+	sort.Slice(dependencies, func(i, j int) bool {
+		if dependencies[i].Upstream != dependencies[j].Upstream {
+			return dependencies[i].Upstream < dependencies[j].Upstream
+		}
+		return dependencies[i].Downstream < dependencies[j].Downstream
+	})
+
+	return dependencies
+}
+
+// Jobs returns the full names of all jobs in the graph, sorted.
+func (graph *DependencyGraph) Jobs() []string {
+	seen := make(map[string]bool)
+	jobs := []string{}
+
+	for _, dependency := range graph.Dependencies() {
+		for _, job := range []string{dependency.Upstream, dependency.Downstream} {
+			if !seen[job] {
+				seen[job] = true
+				jobs = append(jobs, job)
+			}
+		}
+	}
+
+	sort.Strings(jobs)
+	return jobs
+}
+
+// WriteDOT writes the graph in the DOT language of Graphviz. Dependencies that were only declared in the configuration
+// and never triggered a retained build are dashed.
+func (graph *DependencyGraph) WriteDOT(w io.Writer) error {
+	var b strings.Builder
+
+	b.WriteString("digraph jenkins {\n")
+	b.WriteString("  rankdir=LR;\n")
+	for _, job := range graph.Jobs() {
+		fmt.Fprintf(&b, "  %q;\n", job)
+	}
+	for _, dependency := range graph.Dependencies() {
+		style := ""
+		if len(dependency.Sources) == 1 && dependency.Sources[0] == ConfigDependency {
+			style = " [style=dashed]"
+		}
+		fmt.Fprintf(&b, "  %q -> %q%s;\n", dependency.Upstream, dependency.Downstream, style)
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteJSON writes the jobs and the dependencies of the graph as a JSON object.
+func (graph *DependencyGraph) WriteJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(struct {
+		Jobs         []string     `json:"jobs"`
+		Dependencies []Dependency `json:"dependencies"`
+	}{
+		Jobs:         graph.Jobs(),
+		Dependencies: graph.Dependencies(),
+	})
+}
+
+// parentFullName returns the full name of the job, or of its parent for sub-jobs.
+func (job *Job) parentFullName() string {
+	if job.Folder == "/" {
+		return job.Name
+	}
+	return job.Folder + "/" + job.Name
+}
+
+// resolveJobName turns a job name from a build trigger into a full name. Names are relative to the folder of the job
+// that declares them, can use .. to refer to a parent folder, and are absolute when they start with a /.
+func resolveJobName(folder, name string) string {
+	if !strings.HasPrefix(name, "/") && folder != "/" {
+		name = folder + "/" + name
+	}
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
@@ -0,0 +1,132 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func dependencyGraph(t *testing.T, paths ...string) *DependencyGraph {
+	t.Helper()
+
+	graph := NewDependencyGraph()
+	for _, path := range paths {
+		job, err := JobPath(path).Parse()
+		if err != nil {
+			t.Fatal(err)
+		}
+		graph.Add(job)
+	}
+	return graph
+}
+
+func TestDependencyGraph(t *testing.T) {
+	graph := dependencyGraph(t, successfulJob, failedJob, pipelineJob, matrixConfig)
+
+	expected := []Dependency{
+		{Upstream: "folder/folderjob", Downstream: "folder/failedjob", Sources: []string{BuildDependency, ConfigDependency}},
+		{Upstream: "rootjob", Downstream: "folder/folderjob", Sources: []string{BuildDependency}},
+		{Upstream: "rootjob", Downstream: "folder/pipelinejob", Sources: []string{ConfigDependency}},
+	}
+
+	if dependencies := graph.Dependencies(); !reflect.DeepEqual(dependencies, expected) {
+		t.Errorf("dependencies are %+v, expected %+v", dependencies, expected)
+	}
+}
+
+func TestDependencyGraphDOT(t *testing.T) {
+	graph := dependencyGraph(t, successfulJob, failedJob, pipelineJob)
+
+	var b bytes.Buffer
+	err := graph.WriteDOT(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `digraph jenkins {
+  rankdir=LR;
+  "folder/failedjob";
+  "folder/folderjob";
+  "folder/pipelinejob";
+  "rootjob";
+  "folder/folderjob" -> "folder/failedjob";
+  "rootjob" -> "folder/folderjob";
+  "rootjob" -> "folder/pipelinejob" [style=dashed];
+}
+`
+	if b.String() != expected {
+		t.Errorf("DOT graph is\n%s\nexpected\n%s", b.String(), expected)
+	}
+}
+
+func TestDependencyGraphJSON(t *testing.T) {
+	graph := dependencyGraph(t, successfulJob, failedJob)
+
+	var b bytes.Buffer
+	err := graph.WriteJSON(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded struct {
+		Jobs         []string
+		Dependencies []Dependency
+	}
+	err = json.Unmarshal(b.Bytes(), &decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(decoded.Jobs, []string{"folder/failedjob", "folder/folderjob", "rootjob"}) {
+		t.Errorf("jobs are %v", decoded.Jobs)
+	}
+	if !reflect.DeepEqual(decoded.Dependencies, graph.Dependencies()) {
+		t.Errorf("dependencies are %+v, expected %+v", decoded.Dependencies, graph.Dependencies())
+	}
+}
+
+func TestEmptyDependencyGraphJSON(t *testing.T) {
+	var b bytes.Buffer
+	err := NewDependencyGraph().WriteJSON(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if b.String() != "{\"jobs\":[],\"dependencies\":[]}\n" {
+		t.Errorf("empty graph is %s", b.String())
+	}
+}
+
+func TestResolveJobName(t *testing.T) {
+	tests := []struct {
+		folder   string
+		name     string
+		expected string
+	}{
+		{"/", "rootjob", "rootjob"},
+		{"folder", "failedjob", "folder/failedjob"},
+		{"folder", "../rootjob", "rootjob"},
+		{"folder", "/other/job", "other/job"},
+		{"a/b", "../c/job", "a/c/job"},
+	}
+
+	for _, test := range tests {
+		if name := resolveJobName(test.folder, test.name); name != test.expected {
+			t.Errorf("resolveJobName(%s, %s) is %s, expected %s", test.folder, test.name, name, test.expected)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/common/log"
+)
+
+// The kinds of files in the builds folder of a job that are accounted separately by the DiskScanner.
+const (
+	DiskLogs      = "logs"
+	DiskArtifacts = "artifacts"
+	DiskOther     = "other"
+)
+
+// OtherJobs is the name under which TopJobsPerFolder reports the jobs that didn't make the top of their folder.
+const OtherJobs = "_other"
+
+// JobDiskUsage is the size of the builds folders of a job per kind of file. Matrix configurations, Maven modules and
+// the branches of multibranch projects are accounted to their parent job.
+type JobDiskUsage struct {
+	Folder string
+	Name   string
+	Bytes  map[string]int64
+}
+
+// Total returns the size of all files in the builds folders of the job.
+func (usage *JobDiskUsage) Total() int64 {
+	var total int64
+	for _, bytes := range usage.Bytes {
+		total += bytes
+	}
+	return total
+}
+
+// BuildsDiskUsage is the size of the builds folder of a single job, matrix configuration, Maven module or branch,
+// without the builds of its sub-jobs.
+type BuildsDiskUsage struct {
+	Job   Job
+	Bytes int64
+}
+
+// DiskUsage is the result of a scan of the DiskScanner.
+type DiskUsage struct {
+	Jobs               []JobDiskUsage
+	Builds             []BuildsDiskUsage
+	OrphanedWorkspaces []Workspace
+	StaleJobDirs       []StaleJobDir
+	Timestamp          time.Time
+	Duration           time.Duration
+}
+
+// DiskScanner walks the builds folders of all jobs to account their disk usage, the workspaces of jobs that don't
+// exist anymore and the stale job folders. Walking large Jenkins instances takes long and puts a lot of load on the disk, so it runs separately
+// from the collection of the other metrics, and limits the number of files it looks at per second.
+type DiskScanner struct {
+	opts           JobPathOpts
+	filesPerSecond int
+	mutex          sync.Mutex
+	usage          DiskUsage
+}
+
+// NewDiskScanner creates an instance of DiskScanner that looks at no more than filesPerSecond files per second, or
+// at as many as it can when filesPerSecond is 0. Maven modules are always scanned, since their builds take up space
+// whether they are exported or not.
+func NewDiskScanner(opts JobPathOpts, filesPerSecond int) *DiskScanner {
+	opts.MavenModules = true
+	return &DiskScanner{
+		opts:           opts,
+		filesPerSecond: filesPerSecond,
+	}
+}
+
+// Usage returns the result of the last completed scan. Its Timestamp is zero before the first scan completes.
+func (scanner *DiskScanner) Usage() DiskUsage {
+	scanner.mutex.Lock()
+	defer scanner.mutex.Unlock()
+
+	return scanner.usage
+}
+
+// Scan walks the builds folders of all jobs, the orphaned workspaces and the stale job folders, and makes the result available through Usage
+// when it completes. Folders that can't be read are logged and left out of the result.
+func (scanner *DiskScanner) Scan() error {
+	start := time.Now()
+	limiter := newRateLimiter(scanner.filesPerSecond)
+
+	jobPaths := make(chan JobPath)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- GetJobPaths(scanner.opts, jobPaths)
+	}()
+
+	usages := make(map[[2]string]*JobDiskUsage)
+	var builds []BuildsDiskUsage
+	for jobPath := range jobPaths {
+		job := Job{path: jobPath}
+		job.setName()
+
+		key := [2]string{job.Folder, job.Name}
+		usage, ok := usages[key]
+		if !ok {
+			usage = &JobDiskUsage{Folder: job.Folder, Name: job.Name, Bytes: make(map[string]int64)}
+			usages[key] = usage
+		}
+
+		bytes := make(map[string]int64)
+		scanBuildFiles(filepath.Join(string(jobPath), "builds"), bytes, limiter)
+		var total int64
+		for kind, kindBytes := range bytes {
+			usage.Bytes[kind] += kindBytes
+			total += kindBytes
+		}
+		builds = append(builds, BuildsDiskUsage{
+			Job: Job{
+				Folder:        job.Folder,
+				Name:          job.Name,
+				Axes:          job.Axes,
+				Module:        job.Module,
+				Branch:        job.Branch,
+				IsPullRequest: job.IsPullRequest,
+			},
+			Bytes: total,
+		})
+	}
+	if err := <-errs; err != nil {
+		return err
+	}
+
+	orphans, err := findOrphanedWorkspaces(scanner.opts.Root, limiter)
+	if err != nil {
+		return err
+	}
+
+	stale, err := findStaleJobDirs(scanner.opts.Root, limiter)
+	if err != nil {
+		return err
+	}
+
+	var jobs []JobDiskUsage
+	for _, usage := range usages {
+		jobs = append(jobs, *usage)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		if jobs[i].Folder != jobs[j].Folder {
+			return jobs[i].Folder < jobs[j].Folder
+		}
+		return jobs[i].Name < jobs[j].Name
+	})
+
+	scanner.mutex.Lock()
+	scanner.usage = DiskUsage{Jobs: jobs, Builds: builds, OrphanedWorkspaces: orphans, StaleJobDirs: stale, Timestamp: start, Duration: time.Since(start)}
+	scanner.mutex.Unlock()
+
+	return nil
+}
+
+// scanBuildFiles adds the sizes of the files in the build folders below buildsPath to bytes by kind. Permalinks are
+// symlinks to build folders, and aren't followed.
+func scanBuildFiles(buildsPath string, bytes map[string]int64, limiter *rateLimiter) {
+	walkFiles(buildsPath, limiter, func(rel string, info os.FileInfo) {
+		bytes[buildFileKind(rel)] += info.Size()
+	})
+}
+
+// walkFiles calls visit with the path relative to root of every regular file below root, looking at no more files per
+// second than the limiter allows, or at as many as it can when the limiter is nil. Builds and workspaces can be removed
+// while they are being walked, so missing files are skipped. Folders that can't be read are logged and skipped, so one
+// of them doesn't throw away the rest of the scan.
+func walkFiles(root string, limiter *rateLimiter, visit func(rel string, info os.FileInfo)) {
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if !os.IsNotExist(err) {
+				log.Warnf("couldn't scan %s: %v", path, err)
+			}
+			if info != nil && info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		limiter.wait()
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		visit(rel, info)
+		return nil
+	})
+}
+
+// buildFileKind returns the kind of a file, from its path relative to the builds folder of the job. Artifacts are
+// archived in the archive folder of a build. Besides the log of the build itself, older pipelines kept a log per step
+// in their workflow folder.
+func buildFileKind(path string) string {
+	parts := strings.Split(filepath.ToSlash(path), "/")
+	if len(parts) > 2 && parts[1] == "archive" {
+		return DiskArtifacts
+	}
+
+	name := parts[len(parts)-1]
+	switch {
+	case name == "log", name == "log.gz", name == "log-index", strings.HasSuffix(name, ".log"):
+		return DiskLogs
+	default:
+		return DiskOther
+	}
+}
+
+// TopJobsPerFolder keeps the n jobs that use the most disk space in every folder, and adds up the others into a single
+// job named OtherJobs, so the usage of the folder still adds up. All jobs are kept when n is 0.
+func TopJobsPerFolder(jobs []JobDiskUsage, n int) []JobDiskUsage {
+	if n <= 0 {
+		return jobs
+	}
+
+	folders := make(map[string][]JobDiskUsage)
+	var order []string
+	for _, job := range jobs {
+		if _, ok := folders[job.Folder]; !ok {
+			order = append(order, job.Folder)
+		}
+		folders[job.Folder] = append(folders[job.Folder], job)
+	}
+
+	var top []JobDiskUsage
+	for _, folder := range order {
+		folderJobs := folders[folder]
+		sort.SliceStable(folderJobs, func(i, j int) bool {
+			return folderJobs[i].Total() > folderJobs[j].Total()
+		})
+		if len(folderJobs) <= n {
+			top = append(top, folderJobs...)
+			continue
+		}
+
+		top = append(top, folderJobs[:n]...)
+		others := JobDiskUsage{Folder: folder, Name: OtherJobs, Bytes: make(map[string]int64)}
+		for _, job := range folderJobs[n:] {
+			for kind, bytes := range job.Bytes {
+				others.Bytes[kind] += bytes
+			}
+		}
+		top = append(top, others)
+	}
+
+	return top
+}
+
+// rateLimiter spreads calls to wait so they don't happen more often than the given rate per second.
+type rateLimiter struct {
+	rate  int
+	start time.Time
+	count int
+}
+
+func newRateLimiter(rate int) *rateLimiter {
+	return &rateLimiter{rate: rate, start: time.Now()}
+}
+
+func (limiter *rateLimiter) wait() {
+	if limiter == nil || limiter.rate <= 0 {
+		return
+	}
+
+	limiter.count++
+	due := limiter.start.Add(time.Duration(limiter.count) * time.Second / time.Duration(limiter.rate))
+	if delay := time.Until(due); delay > 0 {
+		time.Sleep(delay)
+	}
+}
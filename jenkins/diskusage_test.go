@@ -0,0 +1,198 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func fileSizes(t *testing.T, paths ...string) int64 {
+	var size int64
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		size += info.Size()
+	}
+	return size
+}
+
+func TestDiskScanner(t *testing.T) {
+	scanner := NewDiskScanner(JobPathOpts{Root: "testdata"}, 0)
+
+	if usage := scanner.Usage(); !usage.Timestamp.IsZero() {
+		t.Errorf("usage before the first scan has timestamp %v, expected none", usage.Timestamp)
+	}
+
+	if err := scanner.Scan(); err != nil {
+		t.Fatal(err)
+	}
+
+	usage := scanner.Usage()
+	if usage.Timestamp.IsZero() {
+		t.Error("usage after a scan doesn't have a timestamp")
+	}
+
+	jobs := make(map[string]JobDiskUsage)
+	for _, job := range usage.Jobs {
+		jobs[job.Folder+"/"+job.Name] = job
+	}
+
+	root := "testdata/jobs/rootjob/builds/1/"
+	expected := map[string]int64{
+		DiskLogs:      fileSizes(t, root+"log"),
+		DiskArtifacts: fileSizes(t, root+"archive/dist/VERSION"),
+		DiskOther:     fileSizes(t, root+"build.xml", root+"changelog.xml"),
+	}
+	if bytes := jobs["//rootjob"].Bytes; !reflect.DeepEqual(bytes, expected) {
+		t.Errorf("disk usage of rootjob is %v, expected %v", bytes, expected)
+	}
+
+	if len(usage.OrphanedWorkspaces) != 4 {
+		t.Errorf("found %d orphaned workspaces, expected %d", len(usage.OrphanedWorkspaces), 4)
+	}
+
+	// the configurations are accounted to the matrix project
+	logs := fileSizes(t,
+		"testdata/jobs/matrixjob/builds/1/log",
+		"testdata/jobs/matrixjob/configurations/axis-jdk/11/axis-os/linux/builds/1/log",
+		"testdata/jobs/matrixjob/configurations/axis-jdk/17/axis-os/linux/builds/1/log",
+	)
+	if bytes := jobs["//matrixjob"].Bytes[DiskLogs]; bytes != logs {
+		t.Errorf("log size of matrixjob is %d, expected %d", bytes, logs)
+	}
+
+	// but the size of their own builds is reported too
+	config := "testdata/jobs/matrixjob/configurations/axis-jdk/11/axis-os/linux/builds/1/"
+	expectedBuilds := BuildsDiskUsage{
+		Job:   Job{Folder: "/", Name: "matrixjob", Axes: "jdk=11,os=linux"},
+		Bytes: fileSizes(t, config+"build.xml", config+"log"),
+	}
+	found := false
+	for _, builds := range usage.Builds {
+		if builds.Job.Name == "matrixjob" && builds.Job.Axes == expectedBuilds.Job.Axes {
+			found = true
+			if builds.Bytes != expectedBuilds.Bytes {
+				t.Errorf("builds of %v take %d bytes, expected %d", builds.Job, builds.Bytes, expectedBuilds.Bytes)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("no builds size for %v", expectedBuilds.Job)
+	}
+}
+
+func TestBuildFileKind(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"1/log", DiskLogs},
+		{"1/log.gz", DiskLogs},
+		{"1/workflow/5.log", DiskLogs},
+		{"1/archive/target/app.jar", DiskArtifacts},
+		{"1/archive/app.log", DiskArtifacts},
+		{"1/build.xml", DiskOther},
+		{"1/workflow/5.xml", DiskOther},
+		{"legacyIds", DiskOther},
+		{"archive", DiskOther},
+	}
+
+	for _, test := range tests {
+		if kind := buildFileKind(test.path); kind != test.expected {
+			t.Errorf("kind of %s is %s, expected %s", test.path, kind, test.expected)
+		}
+	}
+}
+
+func TestTopJobsPerFolder(t *testing.T) {
+	jobs := []JobDiskUsage{
+		{Folder: "/", Name: "small", Bytes: map[string]int64{DiskLogs: 10}},
+		{Folder: "team", Name: "big", Bytes: map[string]int64{DiskArtifacts: 1000, DiskLogs: 100}},
+		{Folder: "team", Name: "medium", Bytes: map[string]int64{DiskLogs: 500}},
+		{Folder: "team", Name: "small", Bytes: map[string]int64{DiskLogs: 20, DiskOther: 5}},
+	}
+
+	expected := []JobDiskUsage{
+		{Folder: "/", Name: "small", Bytes: map[string]int64{DiskLogs: 10}},
+		{Folder: "team", Name: "big", Bytes: map[string]int64{DiskArtifacts: 1000, DiskLogs: 100}},
+		{Folder: "team", Name: OtherJobs, Bytes: map[string]int64{DiskLogs: 520, DiskOther: 5}},
+	}
+
+	if top := TopJobsPerFolder(jobs, 1); !reflect.DeepEqual(top, expected) {
+		t.Errorf("top jobs are %+v, expected %+v", top, expected)
+	}
+
+	if top := TopJobsPerFolder(jobs, 0); len(top) != len(jobs) {
+		t.Errorf("kept %d jobs without a limit, expected %d", len(top), len(jobs))
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	limiter := newRateLimiter(100)
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		limiter.wait()
+	}
+
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("10 calls at 100 per second took %v, expected at least %v", elapsed, 90*time.Millisecond)
+	}
+}
+
+func TestWalkFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "walkfiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"a", "sub/b", "locked/c", "z"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := []string{"a", "locked/c", "sub/b", "z"}
+	// root can read the folder anyway
+	if os.Geteuid() != 0 {
+		if err := os.Chmod(filepath.Join(dir, "locked"), 0); err != nil {
+			t.Fatal(err)
+		}
+		defer os.Chmod(filepath.Join(dir, "locked"), 0755)
+		expected = []string{"a", "sub/b", "z"}
+	}
+
+	var visited []string
+	walkFiles(dir, nil, func(rel string, info os.FileInfo) {
+		visited = append(visited, filepath.ToSlash(rel))
+	})
+	if !reflect.DeepEqual(visited, expected) {
+		t.Errorf("visited %v, expected %v", visited, expected)
+	}
+
+	walkFiles(filepath.Join(dir, "missing"), nil, func(rel string, info os.FileInfo) {
+		t.Errorf("visited %s in a missing folder", rel)
+	})
+}
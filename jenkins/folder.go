@@ -0,0 +1,120 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// FolderSummary aggregates the jobs in a folder. A recursive summary includes the jobs in all of its sub-folders,
+// a non-recursive one only the jobs directly in the folder.
+type FolderSummary struct {
+	Folder       string
+	Recursive    bool
+	Jobs         int
+	FailedJobs   int
+	DisabledJobs int
+	// WorstHealth is the lowest health score of the jobs, like the WorstChildHealthMetric of the Folders plugin.
+	// It is -1 when none of the jobs has a health score.
+	WorstHealth   int
+	BuildDuration time.Duration
+}
+
+type folderKey struct {
+	folder    string
+	recursive bool
+}
+
+// FolderAggregator builds the summaries of all folders from the jobs that are added to it.
+type FolderAggregator struct {
+	summaries map[folderKey]*FolderSummary
+}
+
+// NewFolderAggregator creates an instance of FolderAggregator.
+func NewFolderAggregator() *FolderAggregator {
+	return &FolderAggregator{
+		summaries: make(map[folderKey]*FolderSummary),
+	}
+}
+
+// Add adds the job to the summary of its folder, and to the recursive summaries of that folder and all of its parents,
+// up to the root folder /. Matrix configurations and Maven modules are part of their parent job, so they are skipped.
+// Multibranch branches are jobs of their own and are counted in the folder of their project.
+func (aggregator *FolderAggregator) Add(job Job) {
+	if job.Axes != "" || job.Module != "" {
+		return
+	}
+
+	aggregator.add(folderKey{job.Folder, false}, job)
+	for _, folder := range parentFolders(job.Folder) {
+		aggregator.add(folderKey{folder, true}, job)
+	}
+}
+
+func (aggregator *FolderAggregator) add(key folderKey, job Job) {
+	summary, ok := aggregator.summaries[key]
+	if !ok {
+		summary = &FolderSummary{Folder: key.folder, Recursive: key.recursive, WorstHealth: -1}
+		aggregator.summaries[key] = summary
+	}
+
+	summary.Jobs++
+	if job.LastBuild.Result == "FAILURE" {
+		summary.FailedJobs++
+	}
+	if job.Config.Disabled {
+		summary.DisabledJobs++
+	}
+	if health, ok := job.HealthScore(); ok && (summary.WorstHealth == -1 || health < summary.WorstHealth) {
+		summary.WorstHealth = health
+	}
+	for _, build := range job.Builds {
+		summary.BuildDuration += time.Duration(build.Duration) * time.Millisecond
+	}
+}
+
+// Summaries returns the summaries of all folders, ordered by folder and with the non-recursive summary first.
+func (aggregator *FolderAggregator) Summaries() []FolderSummary {
+	var summaries []FolderSummary
+	for _, summary := range aggregator.summaries {
+		summaries = append(summaries, *summary)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Folder != summaries[j].Folder {
+			return summaries[i].Folder < summaries[j].Folder
+		}
+		return !summaries[i].Recursive && summaries[j].Recursive
+	})
+
+	return summaries
+}
+
+// parentFolders returns the folder itself followed by all of its parents, ending with the root folder /.
+func parentFolders(folder string) []string {
+	var folders []string
+
+	for folder != "/" {
+		folders = append(folders, folder)
+		i := strings.LastIndex(folder, "/")
+		if i == -1 {
+			break
+		}
+		folder = folder[:i]
+	}
+
+	return append(folders, "/")
+}
@@ -0,0 +1,83 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFolderAggregator(t *testing.T) {
+	healthy := jobWithResults("SUCCESS", "SUCCESS")
+	healthy.Folder = "team"
+	healthy.Builds[0].Duration = 60000
+	healthy.Builds[1].Duration = 30000
+
+	failing := jobWithResults("FAILURE", "SUCCESS", "SUCCESS", "SUCCESS", "SUCCESS")
+	failing.Folder = "team/nightly"
+	failing.LastBuild = failing.Builds[0]
+
+	disabled := Job{Folder: "team/nightly", Config: JobConfig{Disabled: true}}
+
+	root := jobWithResults("SUCCESS")
+	root.Folder = "/"
+
+	matrixConfiguration := jobWithResults("FAILURE")
+	matrixConfiguration.Folder = "team"
+	matrixConfiguration.Axes = "jdk=11"
+
+	aggregator := NewFolderAggregator()
+	for _, job := range []Job{healthy, failing, disabled, root, matrixConfiguration} {
+		aggregator.Add(job)
+	}
+
+	expected := []FolderSummary{
+		{Folder: "/", Recursive: false, Jobs: 1, WorstHealth: 100},
+		{Folder: "/", Recursive: true, Jobs: 4, FailedJobs: 1, DisabledJobs: 1, WorstHealth: 80, BuildDuration: 90 * time.Second},
+		{Folder: "team", Recursive: false, Jobs: 1, WorstHealth: 100, BuildDuration: 90 * time.Second},
+		{Folder: "team", Recursive: true, Jobs: 3, FailedJobs: 1, DisabledJobs: 1, WorstHealth: 80, BuildDuration: 90 * time.Second},
+		{Folder: "team/nightly", Recursive: false, Jobs: 2, FailedJobs: 1, DisabledJobs: 1, WorstHealth: 80},
+		{Folder: "team/nightly", Recursive: true, Jobs: 2, FailedJobs: 1, DisabledJobs: 1, WorstHealth: 80},
+	}
+
+	summaries := aggregator.Summaries()
+	if !reflect.DeepEqual(summaries, expected) {
+		t.Errorf("summaries are\n%+v\nexpected\n%+v", summaries, expected)
+	}
+}
+
+func TestFolderWithoutHealth(t *testing.T) {
+	aggregator := NewFolderAggregator()
+	aggregator.Add(Job{Folder: "empty"})
+
+	summary := aggregator.Summaries()[0]
+	if summary.WorstHealth != -1 {
+		t.Errorf("summary.WorstHealth is %d, expected %d", summary.WorstHealth, -1)
+	}
+}
+
+func TestParentFolders(t *testing.T) {
+	tests := map[string][]string{
+		"/":     {"/"},
+		"a":     {"a", "/"},
+		"a/b/c": {"a/b/c", "a/b", "a", "/"},
+	}
+
+	for folder, expected := range tests {
+		if folders := parentFolders(folder); !reflect.DeepEqual(folders, expected) {
+			t.Errorf("parent folders of %s are %v, expected %v", folder, folders, expected)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"sort"
+	"time"
+)
+
+type run struct {
+	start time.Time
+	end   time.Time
+}
+
+// ForecastDemand simulates the timer triggers of the jobs for the given number of hours from start, and returns the
+// expected number of concurrently running builds during each of those hours per assigned node label. Every build is
+// assumed to take the median duration of the retained builds of its job. Builds that were started before start but
+// are still running count as well.
+func ForecastDemand(jobs []Job, start time.Time, hours int) map[string][]float64 {
+	demand := make(map[string][]float64)
+	end := start.Add(time.Duration(hours) * time.Hour)
+
+	for _, job := range jobs {
+		if job.Config.Disabled {
+			continue
+		}
+
+		duration := medianDuration(job.Builds)
+		if duration == 0 {
+			continue
+		}
+
+		for _, trigger := range job.Config.Triggers {
+			if trigger.Type != TimerTrigger {
+				continue
+			}
+			schedule, err := ParseCronTab(trigger.Spec, job.FullName())
+			if err != nil {
+				continue
+			}
+
+			label := job.Config.AssignedNode
+			if _, ok := demand[label]; !ok {
+				demand[label] = make([]float64, hours)
+			}
+
+			for _, r := range simulateRuns(schedule, start.Add(-duration), end, duration, job.Config.ConcurrentBuild) {
+				addRun(demand[label], r, start)
+			}
+		}
+	}
+
+	return demand
+}
+
+// simulateRuns returns the builds the schedule starts between from and to. Unless the job allows concurrent builds,
+// Jenkins holds a new build in the queue until the running one is done, and merges it with builds that are already
+// waiting there.
+func simulateRuns(schedule *CronTab, from, to time.Time, duration time.Duration, concurrent bool) []run {
+	var runs []run
+
+	fire, ok := schedule.Next(from.Add(-time.Minute))
+	for ok && fire.Before(to) {
+		start := fire
+		if n := len(runs); !concurrent && n > 0 && fire.Before(runs[n-1].end) {
+			if runs[n-1].start.After(fire) {
+				fire, ok = schedule.Next(fire)
+				continue
+			}
+			start = runs[n-1].end
+		}
+
+		runs = append(runs, run{start: start, end: start.Add(duration)})
+		fire, ok = schedule.Next(fire)
+	}
+
+	return runs
+}
+
+// addRun adds the share of every hour the run overlaps with to the demand of that hour.
+func addRun(demand []float64, r run, start time.Time) {
+	for hour := range demand {
+		from := start.Add(time.Duration(hour) * time.Hour)
+		to := from.Add(time.Hour)
+
+		if r.start.After(from) {
+			from = r.start
+		}
+		if r.end.Before(to) {
+			to = r.end
+		}
+		if to.After(from) {
+			demand[hour] += to.Sub(from).Hours()
+		}
+	}
+}
+
+// medianDuration returns the median duration of the completed builds, or 0 if there are none.
+func medianDuration(builds []Build) time.Duration {
+	var durations []int
+	for _, build := range builds {
+		if build.Result != "" && build.Duration > 0 {
+			durations = append(durations, build.Duration)
+		}
+	}
+
+	if len(durations) == 0 {
+		return 0
+	}
+
+	sort.Ints(durations)
+	median := durations[len(durations)/2]
+	if len(durations)%2 == 0 {
+		median = (durations[len(durations)/2-1] + median) / 2
+	}
+
+	return time.Duration(median) * time.Millisecond
+}
@@ -0,0 +1,109 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func scheduledJob(name, spec, node string, concurrent bool, durations ...time.Duration) Job {
+	job := Job{
+		Name:   name,
+		Folder: "/",
+		Config: JobConfig{
+			AssignedNode:    node,
+			ConcurrentBuild: concurrent,
+			Triggers:        []Trigger{{Type: TimerTrigger, Spec: "TZ=UTC\n" + spec}},
+		},
+	}
+	for _, duration := range durations {
+		job.Builds = append(job.Builds, Build{Result: "SUCCESS", Duration: int(duration / time.Millisecond)})
+	}
+	return job
+}
+
+func assertDemand(t *testing.T, demand []float64, expected []float64) {
+	t.Helper()
+	if len(demand) != len(expected) {
+		t.Fatalf("demand has %d hours, expected %d", len(demand), len(expected))
+	}
+	for hour := range expected {
+		if math.Abs(demand[hour]-expected[hour]) > 1e-9 {
+			t.Errorf("demand in hour %d is %f, expected %f", hour, demand[hour], expected[hour])
+		}
+	}
+}
+
+func TestForecastDemand(t *testing.T) {
+	start := time.Date(2019, time.November, 8, 0, 0, 0, 0, time.UTC)
+	jobs := []Job{
+		scheduledJob("nightly", "0 2 * * *", "linux", false, 20*time.Minute, 30*time.Minute, 2*time.Hour),
+		scheduledJob("backup", "30 2 * * *", "linux", false, time.Hour),
+		scheduledJob("report", "0 3 * * *", "windows", false, 15*time.Minute),
+		scheduledJob("disabled", "0 2 * * *", "linux", false, time.Hour),
+		scheduledJob("new", "0 2 * * *", "linux", false),
+	}
+	jobs[3].Config.Disabled = true
+
+	demand := ForecastDemand(jobs, start, 4)
+
+	assertDemand(t, demand["linux"], []float64{0, 0, 1, 0.5})
+	assertDemand(t, demand["windows"], []float64{0, 0, 0, 0.25})
+}
+
+func TestForecastDemandRunningBuilds(t *testing.T) {
+	start := time.Date(2019, time.November, 8, 0, 0, 0, 0, time.UTC)
+	jobs := []Job{
+		scheduledJob("long", "0 23 * * *", "", false, 3*time.Hour),
+	}
+
+	demand := ForecastDemand(jobs, start, 3)
+
+	assertDemand(t, demand[""], []float64{1, 1, 0})
+}
+
+func TestForecastDemandQueue(t *testing.T) {
+	start := time.Date(2019, time.November, 8, 0, 0, 0, 0, time.UTC)
+	jobs := []Job{
+		// every build takes 25 minutes, but one is triggered every 10 minutes
+		scheduledJob("serial", "*/10 * * * *", "", false, 25*time.Minute),
+		scheduledJob("parallel", "*/10 * * * *", "", true, 25*time.Minute),
+	}
+
+	demand := ForecastDemand(jobs[:1], start, 1)
+	assertDemand(t, demand[""], []float64{1})
+
+	demand = ForecastDemand(jobs[1:], start, 1)
+	assertDemand(t, demand[""], []float64{2.5})
+}
+
+func TestMedianDuration(t *testing.T) {
+	builds := []Build{
+		{Result: "SUCCESS", Duration: 4000},
+		{Result: "FAILURE", Duration: 1000},
+		{Result: "", Duration: 0},
+		{Result: "SUCCESS", Duration: 2000},
+		{Result: "SUCCESS", Duration: 9000},
+	}
+
+	if median := medianDuration(builds); median != 3*time.Second {
+		t.Errorf("median duration is %v, expected %v", median, 3*time.Second)
+	}
+
+	if median := medianDuration(nil); median != 0 {
+		t.Errorf("median duration without builds is %v, expected 0", median)
+	}
+}
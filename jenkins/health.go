@@ -0,0 +1,122 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// stabilityBuilds is the number of completed builds Jenkins considers for the build stability of a job.
+const stabilityBuilds = 5
+
+// BuildStability returns the build stability health score of the job, the percentage of its last five completed
+// builds that didn't fail, the way Jenkins computes it. Aborted, not built and running builds are skipped. The second
+// return value is false when the job doesn't have any completed builds.
+func (job *Job) BuildStability() (int, bool) {
+	var total, failed int
+
+	for _, build := range job.Builds {
+		if total == stabilityBuilds {
+			break
+		}
+
+		switch build.Result {
+		case "SUCCESS", "UNSTABLE":
+			total++
+		case "FAILURE":
+			total++
+			failed++
+		}
+	}
+
+	if total == 0 {
+		return 0, false
+	}
+
+	return 100 * (total - failed) / total, true
+}
+
+// TestResult holds the totals of the JUnit test results of a build.
+type TestResult struct {
+	Total   int
+	Failed  int
+	Skipped int
+	// HealthScaleFactor is the health report amplification factor of the JUnit publisher. A factor of 1 means the
+	// health score drops by 1 for every percent of failing tests, 0 disables the health report of the tests.
+	HealthScaleFactor float64
+}
+
+type testResultActionXML struct {
+	FailCount         int    `xml:"failCount"`
+	SkipCount         int    `xml:"skipCount"`
+	TotalCount        int    `xml:"totalCount"`
+	HealthScaleFactor string `xml:"healthScaleFactor"`
+}
+
+func parseTestResult(action *testResultActionXML) *TestResult {
+	if action == nil {
+		return nil
+	}
+
+	// builds recorded before the factor was configurable use the default of 1
+	scaleFactor, err := strconv.ParseFloat(strings.TrimSpace(action.HealthScaleFactor), 64)
+	if err != nil {
+		scaleFactor = 1
+	}
+
+	return &TestResult{
+		Total:             action.TotalCount,
+		Failed:            action.FailCount,
+		Skipped:           action.SkipCount,
+		HealthScaleFactor: scaleFactor,
+	}
+}
+
+// Health returns the health score of the test results the way the JUnit plugin computes it. The second return value is
+// false when the health report is disabled.
+func (tests *TestResult) Health() (int, bool) {
+	if tests == nil || tests.HealthScaleFactor == 0 {
+		return 0, false
+	}
+	if tests.Total == 0 {
+		return 100, true
+	}
+
+	health := 1 - tests.HealthScaleFactor*float64(tests.Failed)/float64(tests.Total)
+	return int(100 * math.Max(0, math.Min(1, health))), true
+}
+
+// HealthScore returns the health score Jenkins shows as the weather of the job: the worst of the build stability and
+// the health of the test results of the last completed build. The second return value is false when the job doesn't
+// have any completed builds.
+func (job *Job) HealthScore() (int, bool) {
+	score, ok := job.BuildStability()
+	if !ok {
+		return 0, false
+	}
+
+	for _, build := range job.Builds {
+		if build.Result == "" {
+			continue
+		}
+		if health, ok := build.Tests.Health(); ok && health < score {
+			score = health
+		}
+		break
+	}
+
+	return score, true
+}
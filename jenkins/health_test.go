@@ -0,0 +1,142 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import "testing"
+
+// jobWithResults creates a job with builds with the given results, ordered from newest to oldest.
+func jobWithResults(results ...string) Job {
+	var job Job
+	for i, result := range results {
+		job.Builds = append(job.Builds, Build{Number: len(results) - i, Result: result})
+	}
+	return job
+}
+
+func TestBuildStability(t *testing.T) {
+	tests := []struct {
+		results  []string
+		expected int
+	}{
+		{[]string{"SUCCESS", "SUCCESS", "SUCCESS", "SUCCESS", "SUCCESS"}, 100},
+		{[]string{"FAILURE", "SUCCESS", "SUCCESS", "SUCCESS", "SUCCESS"}, 80},
+		{[]string{"FAILURE", "FAILURE", "SUCCESS", "SUCCESS", "SUCCESS"}, 60},
+		{[]string{"FAILURE", "FAILURE", "FAILURE", "SUCCESS", "SUCCESS"}, 40},
+		{[]string{"FAILURE", "FAILURE", "FAILURE", "FAILURE", "SUCCESS"}, 20},
+		{[]string{"FAILURE", "FAILURE", "FAILURE", "FAILURE", "FAILURE"}, 0},
+		// unstable builds don't count as failures
+		{[]string{"UNSTABLE", "UNSTABLE", "SUCCESS"}, 100},
+		// only the last five completed builds count
+		{[]string{"SUCCESS", "SUCCESS", "SUCCESS", "SUCCESS", "SUCCESS", "FAILURE"}, 100},
+		// aborted and running builds are skipped
+		{[]string{"", "ABORTED", "FAILURE", "NOT_BUILT", "SUCCESS", "SUCCESS", "SUCCESS", "SUCCESS"}, 80},
+		// the score is rounded down
+		{[]string{"FAILURE", "SUCCESS", "SUCCESS"}, 66},
+	}
+
+	for _, test := range tests {
+		job := jobWithResults(test.results...)
+		score, ok := job.BuildStability()
+		if !ok {
+			t.Errorf("build stability of %v is missing", test.results)
+			continue
+		}
+		if score != test.expected {
+			t.Errorf("build stability of %v is %d, expected %d", test.results, score, test.expected)
+		}
+	}
+}
+
+func TestBuildStabilityWithoutCompletedBuilds(t *testing.T) {
+	job := jobWithResults("", "ABORTED")
+
+	if _, ok := job.BuildStability(); ok {
+		t.Error("job without completed builds has a build stability")
+	}
+}
+
+func TestTestResultHealth(t *testing.T) {
+	tests := []struct {
+		result   TestResult
+		expected int
+	}{
+		{TestResult{Total: 100, Failed: 0, HealthScaleFactor: 1}, 100},
+		{TestResult{Total: 100, Failed: 10, HealthScaleFactor: 1}, 90},
+		{TestResult{Total: 100, Failed: 10, HealthScaleFactor: 5}, 50},
+		{TestResult{Total: 100, Failed: 30, HealthScaleFactor: 5}, 0},
+		{TestResult{Total: 3, Failed: 1, HealthScaleFactor: 1}, 66},
+		{TestResult{Total: 0, Failed: 0, HealthScaleFactor: 1}, 100},
+	}
+
+	for _, test := range tests {
+		health, ok := test.result.Health()
+		if !ok {
+			t.Errorf("health of %+v is missing", test.result)
+			continue
+		}
+		if health != test.expected {
+			t.Errorf("health of %+v is %d, expected %d", test.result, health, test.expected)
+		}
+	}
+}
+
+func TestTestResultHealthDisabled(t *testing.T) {
+	var missing *TestResult
+	if _, ok := missing.Health(); ok {
+		t.Error("build without test results has a test health")
+	}
+
+	disabled := &TestResult{Total: 10, Failed: 5, HealthScaleFactor: 0}
+	if _, ok := disabled.Health(); ok {
+		t.Error("test results with a scale factor of 0 have a test health")
+	}
+}
+
+func TestHealthScore(t *testing.T) {
+	job := Job{
+		path: JobPath(successfulJob),
+	}
+
+	err := job.fetch()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &TestResult{Total: 8, Failed: 1, Skipped: 2, HealthScaleFactor: 2}
+	if *job.LastSuccessfulBuild.Tests != *expected {
+		t.Errorf("test results are %+v, expected %+v", job.LastSuccessfulBuild.Tests, expected)
+	}
+
+	// the build is stable, but 1 of 8 tests failing with a scale factor of 2 costs 25 points
+	score, ok := job.HealthScore()
+	if !ok || score != 75 {
+		t.Errorf("health score is %d, expected %d", score, 75)
+	}
+}
+
+func TestHealthScoreUsesWorstReport(t *testing.T) {
+	job := jobWithResults("", "FAILURE", "SUCCESS")
+	job.Builds[1].Tests = &TestResult{Total: 10, Failed: 1, HealthScaleFactor: 1}
+
+	score, ok := job.HealthScore()
+	if !ok || score != 50 {
+		t.Errorf("health score is %d, expected %d", score, 50)
+	}
+
+	job.Builds[1].Tests.Failed = 7
+	score, ok = job.HealthScore()
+	if !ok || score != 30 {
+		t.Errorf("health score is %d, expected %d", score, 30)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"path/filepath"
+)
+
+// Indexing represents the last branch indexing of a multibranch project or the last scan of an organization folder.
+type Indexing struct {
+	Name      string
+	Folder    string
+	Result    string
+	Timestamp int
+	Duration  int
+}
+
+type indexingXML struct {
+	Result    string `xml:"result"`
+	Timestamp int    `xml:"timestamp"`
+	Duration  int    `xml:"duration"`
+}
+
+// indexingFiles are the places where computed folders store their last computation. Multibranch projects use
+// indexing/, organization folders use the computation/ folder of their parent class.
+var indexingFiles = []string{
+	filepath.Join("indexing", "indexing.xml"),
+	filepath.Join("computation", "computation.xml"),
+}
+
+// GetIndexings recursively searches a given folder for multibranch projects and organization folders and returns the
+// result of their last indexing. Use WalkJobs to find the job paths in the same walk.
+func GetIndexings(opts JobPathOpts) ([]Indexing, error) {
+	tree, err := walkJobTree(opts)
+	return tree.Indexings, err
+}
+
+func parseIndexing(path string) (Indexing, error) {
+	var indexing Indexing
+
+	byteValue, err := ioutil.ReadFile(path)
+	if err != nil {
+		return indexing, err
+	}
+
+	var raw indexingXML
+	err = xml.Unmarshal(forceXMLVersion(byteValue), &raw)
+	if err != nil {
+		return indexing, err
+	}
+
+	indexing.Result = raw.Result
+	indexing.Timestamp = raw.Timestamp
+	indexing.Duration = raw.Duration
+
+	return indexing, nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestGetIndexings(t *testing.T) {
+	indexings, err := GetIndexings(JobPathOpts{Root: "testdata"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]Indexing{
+		"multibranch":  {Name: "multibranch", Folder: "/", Result: "SUCCESS", Timestamp: 1573229900000, Duration: 2417},
+		"organization": {Name: "organization", Folder: "/", Result: "FAILURE", Timestamp: 1573231000000, Duration: 30712},
+	}
+
+	if len(indexings) != len(expected) {
+		t.Fatalf("len(indexings) is %d, expected %d", len(indexings), len(expected))
+	}
+
+	for _, indexing := range indexings {
+		if indexing != expected[indexing.Name] {
+			t.Errorf("indexing is %+v, expected %+v", indexing, expected[indexing.Name])
+		}
+	}
+}
+
+func TestGetIndexingsIgnoreList(t *testing.T) {
+	indexings, err := GetIndexings(JobPathOpts{Root: "testdata", IgnoreList: []string{"organization"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(indexings) != 1 {
+		t.Errorf("len(indexings) is %d, expected %d", len(indexings), 1)
+	}
+}
+
+func TestGetIndexingsMalformed(t *testing.T) {
+	indexings, err := GetIndexings(JobPathOpts{Root: filepath.Join("testdata", "malformedhome")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(indexings) != 1 || indexings[0].Name != "multibranch" {
+		t.Errorf("indexings is %+v, expected only the indexing of multibranch", indexings)
+	}
+}
+
+func TestGetIndexingsNonExistentPath(t *testing.T) {
+	_, err := GetIndexings(JobPathOpts{Root: filepath.Join("testdata", "foobar")})
+	if err == nil {
+		t.Error("non existent path should return an error")
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+// Item is a job or folder of Jenkins, as found by its config.xml. Unlike GetJobPaths, which only finds jobs with a
+// builds folder, this includes folders, multibranch projects, organization folders and jobs that were never built.
+// Matrix configurations, Maven modules and the branches of multibranch projects are part of their parent item.
+type Item struct {
+	Name   string
+	Folder string
+	Config JobConfig
+}
+
+// FullName returns the name Jenkins uses to identify the item, including its folders.
+func (item *Item) FullName() string {
+	if item.Folder == "/" {
+		return item.Name
+	}
+	return item.Folder + "/" + item.Name
+}
+
+// GetItems returns all items in the jobs folder of the Jenkins folder at opts.Root and in the folders below it,
+// ordered by full name. Items on the ignore list are skipped together with the items inside them. Items whose
+// config.xml can't be parsed are logged and skipped. Use WalkJobs to find the job paths in the same walk.
+func GetItems(opts JobPathOpts) ([]Item, error) {
+	tree, err := walkJobTree(opts)
+	return tree.Items, err
+}
@@ -0,0 +1,73 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestGetItems(t *testing.T) {
+	items, err := GetItems(JobPathOpts{Root: "testdata"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		fullName string
+		folder   string
+		itemType string
+	}{
+		{"folder", "/", "folder"},
+		{"folder/failedjob", "folder", "freestyle"},
+		{"folder/folderjob", "folder", "freestyle"},
+		{"folder/jobwithoutbuilds", "folder", "freestyle"},
+		{"folder/pipelinejob", "folder", "pipeline"},
+		{"matrixjob", "/", "matrix"},
+		{"mavenjob", "/", "maven"},
+		{"multibranch", "/", "multibranch"},
+		{"organization", "/", "organization-folder"},
+		{"rootjob", "/", "freestyle"},
+	}
+
+	if len(items) != len(expected) {
+		t.Fatalf("len(items) is %d, expected %d", len(items), len(expected))
+	}
+
+	for i, item := range items {
+		if item.FullName() != expected[i].fullName || item.Folder != expected[i].folder || item.Config.Type != expected[i].itemType {
+			t.Errorf("items[%d] is (%s, %s, %s), expected (%s, %s, %s)", i, item.FullName(), item.Folder, item.Config.Type, expected[i].fullName, expected[i].folder, expected[i].itemType)
+		}
+	}
+}
+
+func TestGetItemsIgnoreList(t *testing.T) {
+	items, err := GetItems(JobPathOpts{Root: "testdata", IgnoreList: []string{"folder"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, item := range items {
+		if item.Name == "folder" || item.Folder == "folder" {
+			t.Errorf("item %s should be ignored", item.FullName())
+		}
+	}
+}
+
+func TestGetItemsNonExistentPath(t *testing.T) {
+	_, err := GetItems(JobPathOpts{Root: filepath.Join("testdata", "foobar")})
+	if err == nil {
+		t.Error("non existent path should return an error")
+	}
+}
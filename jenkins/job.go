@@ -15,24 +15,43 @@ package jenkins
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
+// ErrNoBuilds is returned when parsing a job that doesn't have any builds yet.
+// The job is still populated with everything that doesn't depend on builds, such as its name and configuration.
+var ErrNoBuilds = errors.New("no builds found")
+
 // Job represents a Jenkins job and contains its latest builds.
 type Job struct {
 	path                  JobPath
 	Name                  string
 	Folder                string
+	Config                JobConfig
+	Axes                  string
+	Module                string
+	Branch                string
+	IsPullRequest         bool
 	LastBuild             Build
 	LastSuccessfulBuild   Build
 	LastUnsuccessfulBuild Build
 	LastStableBuild       Build
 	LastUnstableBuild     Build
 	LastFailedBuild       Build
+	Builds                []Build
+	RetainedBuilds        int
+	NextBuildNumber       int
+	newestBuildDir        int
 }
 
 func (job *Job) fetch() error {
@@ -46,6 +65,18 @@ func (job *Job) fetch() error {
 		return fmt.Errorf("%s is not a directory", buildsPath)
 	}
 
+	job.Config, _ = parseJobConfig(string(job.path))
+	job.setName()
+	job.NextBuildNumber, _ = parseNextBuildNumber(string(job.path))
+
+	// Scanned before the permalinks, so jobs whose builds were all removed still report their gap
+	dirInfo, err := scanBuildsDir(buildsPath)
+	if err != nil {
+		return fmt.Errorf("couldn't scan builds for %s: %v", buildsPath, err)
+	}
+	job.RetainedBuilds = dirInfo.count
+	job.newestBuildDir = dirInfo.newest
+
 	permalinksPath := filepath.Join(buildsPath, "permalinks")
 
 	var (
@@ -87,19 +118,138 @@ func (job *Job) fetch() error {
 	if err != nil {
 		return err
 	}
+	job.LastBuild.loadIssues()
+	job.LastSuccessfulBuild.loadCoverage()
+	job.LastFailedBuild.loadPipelineFailures()
+
+	job.Builds, err = parseBuilds(buildsPath)
+	if err != nil {
+		return fmt.Errorf("couldn't parse builds for %s: %v", buildsPath, err)
+	}
+
+	return nil
+}
+
+// setName derives the name and folder of the job from its path. Sub-jobs get the name of their parent job.
+func (job *Job) setName() {
+	jobPath := string(job.path)
+	if parent, axes, ok := splitSubJob(jobPath, "configurations"); ok {
+		job.Axes = parseAxes(axes)
+		jobPath = parent
+	}
+
+	if parent, _, ok := splitSubJob(jobPath, "branches"); ok {
+		job.Branch, job.IsPullRequest = parseBranch(jobPath)
+		jobPath = parent
+	}
+
+	if parent, module, ok := splitSubJob(jobPath, "modules"); ok {
+		job.Module = strings.Replace(module, "$", ":", 1)
+		jobPath = parent
+	}
+
+	job.Folder, job.Name = splitJobName(jobPath)
+}
+
+// FullName returns the name Jenkins uses to identify the job, including its folders and the parent of a sub-job.
+// Jenkins uses it to seed the hash for H in the cron specs of the triggers of the job.
+func (job *Job) FullName() string {
+	tokens := []string{job.parentFullName()}
+
+	switch {
+	case job.Axes != "":
+		tokens = append(tokens, job.Axes)
+	case job.Module != "":
+		tokens = append(tokens, strings.Replace(job.Module, ":", "$", 1))
+	case job.Branch != "":
+		tokens = append(tokens, url.PathEscape(job.Branch))
+	}
+
+	return strings.Join(tokens, "/")
+}
+
+// LastStarted returns the timestamp of the newest build of the job, including builds that are still running.
+func (job *Job) LastStarted() time.Time {
+	timestamp := job.LastBuild.Timestamp
+	if len(job.Builds) > 0 && job.Builds[0].Timestamp > timestamp {
+		timestamp = job.Builds[0].Timestamp
+	}
+	if timestamp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(timestamp)*int64(time.Millisecond))
+}
+
+// MissedSchedule reports whether the job should have been started by the schedule, but wasn't. Builds that are started
+// within the grace period after the schedule fired are still on time, since they might have been waiting in the queue,
+// so the last time the schedule fired before the grace period is the one the job is checked against. Jobs that were
+// never built only missed the schedule when it fired after their configuration was saved.
+func (job *Job) MissedSchedule(schedule *CronTab, now time.Time, grace time.Duration) bool {
+	if job.Config.Disabled {
+		return false
+	}
+
+	expected, ok := schedule.Previous(now.Add(-grace))
+	if !ok {
+		return false
+	}
+
+	since := job.LastStarted()
+	if since.IsZero() {
+		since = job.Config.ModTime
+	}
+	if since.IsZero() {
+		return false
+	}
+
+	return since.Before(expected)
+}
+
+// BuildNumberGap returns the number of builds between the newest build folder and the last build number Jenkins handed
+// out. These builds were removed, or never got to write their build folder.
+func (job *Job) BuildNumberGap() int {
+	if job.NextBuildNumber == 0 || job.NextBuildNumber-1 < job.newestBuildDir {
+		return 0
+	}
+	return job.NextBuildNumber - 1 - job.newestBuildDir
+}
 
+// splitJobName returns the folder and the name of the job or folder at the given path.
+func splitJobName(path string) (string, string) {
 	regex := regexp.MustCompile(`^\S+?\/jobs/`)
-	fixedPath := strings.ReplaceAll(regex.ReplaceAllString(string(job.path), ""), "jobs/", "")
+	fixedPath := strings.ReplaceAll(regex.ReplaceAllString(path, ""), "jobs/", "")
 
 	tokens := strings.Split(fixedPath, "/")
-	job.Name = tokens[len(tokens)-1]
 	if len(tokens) == 1 {
-		job.Folder = "/"
-	} else {
-		job.Folder = strings.Join(tokens[:len(tokens)-1], "/")
+		return "/", tokens[0]
 	}
+	return strings.Join(tokens[:len(tokens)-1], "/"), tokens[len(tokens)-1]
+}
 
-	return nil
+// splitSubJob splits the path of a sub-job (a matrix configuration, branch or Maven module) into the path of its
+// parent job and the part identifying the sub-job. Sub-jobs are stored in a folder directly below their parent, so only
+// the part after the last jobs/ folder is considered.
+func splitSubJob(path, subJobFolder string) (string, string, bool) {
+	start := strings.LastIndex(path, "/jobs/") + 1
+	i := strings.Index(path[start:], "/"+subJobFolder+"/")
+	if i == -1 {
+		return path, "", false
+	}
+	i += start
+
+	return path[:i], path[i+len(subJobFolder)+2:], true
+}
+
+// parseAxes turns the path of a matrix configuration (axis-jdk/11/axis-os/linux) into a label value (jdk=11,os=linux).
+func parseAxes(path string) string {
+	var axes []string
+
+	tokens := strings.Split(path, "/")
+	for i := 0; i+1 < len(tokens); i += 2 {
+		axes = append(axes, strings.TrimPrefix(tokens[i], "axis-")+"="+tokens[i+1])
+	}
+
+	return strings.Join(axes, ",")
 }
 
 func (job *Job) selectLastBuild() (Build, error) {
@@ -132,12 +282,59 @@ func (job *Job) selectLastBuild() (Build, error) {
 	}
 
 	if max == 0 {
-		return lastBuild, fmt.Errorf("no builds found at %s", job.path)
+		return lastBuild, fmt.Errorf("%w at %s", ErrNoBuilds, job.path)
 	}
 
 	return lastBuild, nil
 }
 
+// parseBuilds parses all builds that are still retained in the builds folder, ordered from newest to oldest.
+func parseBuilds(buildsPath string) ([]Build, error) {
+	var builds []Build
+
+	buildDirs, err := ioutil.ReadDir(buildsPath)
+	if err != nil {
+		return builds, err
+	}
+
+	for _, buildDir := range buildDirs {
+		if !buildDir.IsDir() {
+			continue
+		}
+		if _, err := strconv.Atoi(buildDir.Name()); err != nil {
+			continue
+		}
+
+		// the failures of all retained builds are counted, the other data of plugins is only exported for the permalinks
+		build, err := retainedBuilds.get(filepath.Join(buildsPath, buildDir.Name()))
+		if err != nil {
+			// builds that are still running or were only partially removed don't have a usable build.xml
+			continue
+		}
+		builds = append(builds, build)
+	}
+
+	sort.Slice(builds, func(i, j int) bool {
+		return builds[i].Number > builds[j].Number
+	})
+
+	return builds, nil
+}
+
+func parseNextBuildNumber(path string) (int, error) {
+	byteValue, err := ioutil.ReadFile(filepath.Join(path, "nextBuildNumber"))
+	if err != nil {
+		return 0, err
+	}
+
+	nextBuildNumber, err := strconv.Atoi(strings.TrimSpace(string(byteValue)))
+	if err != nil {
+		return 0, fmt.Errorf("invalid nextBuildNumber in %s: %v", path, err)
+	}
+
+	return nextBuildNumber, nil
+}
+
 func parsePermalinks(path string) (map[string]string, error) {
 	permalinks := make(map[string]string)
 
@@ -151,7 +348,7 @@ func parsePermalinks(path string) (map[string]string, error) {
 	for scanner.Scan() {
 		line := strings.Split(scanner.Text(), " ")
 		if len(line) != 2 {
-			return permalinks, fmt.Errorf("unexpected amount of tokens (%s)", len(line))
+			return permalinks, fmt.Errorf("unexpected amount of tokens (%d)", len(line))
 		}
 		permalinks[line[0]] = line[1]
 	}
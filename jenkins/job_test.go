@@ -13,7 +13,11 @@
 
 package jenkins
 
-import "testing"
+import (
+	"errors"
+	"testing"
+	"time"
+)
 
 var (
 	rootfolderJob    = "testdata/jobs/rootjob"
@@ -22,6 +26,9 @@ var (
 	jobWithoutBuilds = "testdata/jobs/folder/jobs/jobwithoutbuilds"
 	nonExistentJob   = "testdata/jobs/foobar"
 	folder           = "testdata/jobs/folder"
+	matrixJob        = "testdata/jobs/matrixjob"
+	matrixConfig     = "testdata/jobs/matrixjob/configurations/axis-jdk/17/axis-os/linux"
+	mavenModule      = "testdata/jobs/mavenjob/modules/com.example$app"
 )
 
 func TestJobFetch(t *testing.T) {
@@ -87,8 +94,8 @@ func TestJobWithoutBuilds(t *testing.T) {
 	}
 
 	err := job.fetch()
-	if err == nil {
-		t.Error("job without builds should return an error when parsed")
+	if !errors.Is(err, ErrNoBuilds) {
+		t.Errorf("job without builds should return ErrNoBuilds when parsed, got %v", err)
 	}
 }
 
@@ -128,3 +135,203 @@ func TestFolderNotAJob(t *testing.T) {
 		t.Error("trying to parse a folder as a job should return an error")
 	}
 }
+
+func TestJobBuilds(t *testing.T) {
+	job := Job{
+		path: JobPath(pipelineJob),
+	}
+
+	err := job.fetch()
+	if err != nil {
+		t.Error(err)
+	}
+
+	if len(job.Builds) != 3 {
+		t.Fatalf("len(job.Builds) is %d, expected %d", len(job.Builds), 3)
+	}
+
+	for i, number := range []int{3, 2, 1} {
+		if job.Builds[i].Number != number {
+			t.Errorf("job.Builds[%d].Number is %d, expected %d", i, job.Builds[i].Number, number)
+		}
+	}
+}
+
+func TestMatrixConfiguration(t *testing.T) {
+	job := Job{
+		path: JobPath(matrixConfig),
+	}
+
+	err := job.fetch()
+	if err != nil {
+		t.Error(err)
+	}
+
+	if job.Name != "matrixjob" {
+		t.Errorf("job.Name is %s, expected %s", job.Name, "matrixjob")
+	}
+
+	if job.Folder != "/" {
+		t.Errorf("job.Folder is %s, expected %s", job.Folder, "/")
+	}
+
+	if job.Axes != "jdk=17,os=linux" {
+		t.Errorf("job.Axes is %s, expected %s", job.Axes, "jdk=17,os=linux")
+	}
+
+	if job.LastBuild.Result != "FAILURE" {
+		t.Errorf("job.LastBuild.Result is %s, expected %s", job.LastBuild.Result, "FAILURE")
+	}
+}
+
+func TestMatrixParent(t *testing.T) {
+	job := Job{
+		path: JobPath(matrixJob),
+	}
+
+	err := job.fetch()
+	if err != nil {
+		t.Error(err)
+	}
+
+	if job.Name != "matrixjob" {
+		t.Errorf("job.Name is %s, expected %s", job.Name, "matrixjob")
+	}
+
+	if job.Axes != "" {
+		t.Errorf("job.Axes is %s, expected no axes", job.Axes)
+	}
+}
+
+func TestMavenModule(t *testing.T) {
+	job := Job{
+		path: JobPath(mavenModule),
+	}
+
+	err := job.fetch()
+	if err != nil {
+		t.Error(err)
+	}
+
+	if job.Name != "mavenjob" {
+		t.Errorf("job.Name is %s, expected %s", job.Name, "mavenjob")
+	}
+
+	if job.Module != "com.example:app" {
+		t.Errorf("job.Module is %s, expected %s", job.Module, "com.example:app")
+	}
+
+	if job.LastBuild.Result != "UNSTABLE" {
+		t.Errorf("job.LastBuild.Result is %s, expected %s", job.LastBuild.Result, "UNSTABLE")
+	}
+}
+
+func TestSplitSubJob(t *testing.T) {
+	tests := []struct {
+		path   string
+		parent string
+		sub    string
+		ok     bool
+	}{
+		{"jenkins/jobs/matrix/configurations/axis-jdk/11", "jenkins/jobs/matrix", "axis-jdk/11", true},
+		{"jenkins/jobs/configurations/jobs/job", "jenkins/jobs/configurations/jobs/job", "", false},
+		{"jenkins/jobs/configurations", "jenkins/jobs/configurations", "", false},
+	}
+
+	for _, test := range tests {
+		parent, sub, ok := splitSubJob(test.path, "configurations")
+		if parent != test.parent || sub != test.sub || ok != test.ok {
+			t.Errorf("splitSubJob(%s) is (%s, %s, %t), expected (%s, %s, %t)", test.path, parent, sub, ok, test.parent, test.sub, test.ok)
+		}
+	}
+}
+
+func TestJobFullName(t *testing.T) {
+	tests := map[string]string{
+		rootfolderJob: "rootjob",
+		successfulJob: "folder/folderjob",
+		matrixConfig:  "matrixjob/jdk=17,os=linux",
+		mavenModule:   "mavenjob/com.example$app",
+		"testdata/jobs/multibranch/branches/feature-login.7ajqb1": "multibranch/feature%2Flogin",
+	}
+
+	for path, expected := range tests {
+		job := Job{path: JobPath(path)}
+		job.setName()
+		if job.FullName() != expected {
+			t.Errorf("full name of %s is %s, expected %s", path, job.FullName(), expected)
+		}
+	}
+}
+
+func TestMissedSchedule(t *testing.T) {
+	schedule, err := ParseCronTab("TZ=UTC\n0 2 * * *", "rootjob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fired := time.Date(2019, time.November, 8, 2, 0, 0, 0, time.UTC)
+	started := Job{LastBuild: Build{Number: 1, Timestamp: int(fired.Add(time.Minute).UnixNano() / int64(time.Millisecond))}}
+	missed := Job{LastBuild: Build{Number: 1, Timestamp: int(fired.Add(-time.Hour).UnixNano() / int64(time.Millisecond))}}
+	stuck := Job{LastBuild: Build{Number: 1, Timestamp: int(fired.Add(-72*time.Hour).UnixNano() / int64(time.Millisecond))}}
+	disabled := Job{Config: JobConfig{Disabled: true}}
+	configuredBefore := Job{Config: JobConfig{ModTime: fired.Add(-time.Hour)}}
+	configuredAfter := Job{Config: JobConfig{ModTime: fired.Add(time.Minute)}}
+
+	tests := []struct {
+		name     string
+		job      Job
+		now      time.Time
+		expected bool
+	}{
+		{"started", started, fired.Add(time.Hour), false},
+		{"missed", missed, fired.Add(time.Hour), true},
+		{"within grace", missed, fired.Add(5 * time.Minute), false},
+		{"missed before grace", stuck, fired.Add(5 * time.Minute), true},
+		{"never built since configured", configuredBefore, fired.Add(time.Hour), true},
+		{"configured after the schedule fired", configuredAfter, fired.Add(time.Hour), false},
+		{"never built without configuration", Job{}, fired.Add(time.Hour), false},
+		{"disabled", disabled, fired.Add(time.Hour), false},
+	}
+
+	for _, test := range tests {
+		if test.job.MissedSchedule(schedule, test.now, 15*time.Minute) != test.expected {
+			t.Errorf("%s: MissedSchedule is %t, expected %t", test.name, !test.expected, test.expected)
+		}
+	}
+}
+
+func TestNextBuildNumber(t *testing.T) {
+	job := Job{
+		path: JobPath(pipelineJob),
+	}
+
+	err := job.fetch()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if job.NextBuildNumber != 4 {
+		t.Errorf("job.NextBuildNumber is %d, expected %d", job.NextBuildNumber, 4)
+	}
+
+	if job.BuildNumberGap() != 0 {
+		t.Errorf("job.BuildNumberGap() is %d, expected %d", job.BuildNumberGap(), 0)
+	}
+}
+
+func TestBuildNumberGap(t *testing.T) {
+	job := Job{
+		path: JobPath(jobWithoutBuilds),
+	}
+
+	job.fetch()
+
+	if job.NextBuildNumber != 4 {
+		t.Errorf("job.NextBuildNumber is %d, expected %d", job.NextBuildNumber, 4)
+	}
+
+	if job.BuildNumberGap() != 3 {
+		t.Errorf("job.BuildNumberGap() is %d, expected %d", job.BuildNumberGap(), 3)
+	}
+}
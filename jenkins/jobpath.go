@@ -18,14 +18,19 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
+
+	"github.com/prometheus/common/log"
 )
 
 // JobPath represents a path to a job on the filesystem.
 type JobPath string
 
+// JobPathOpts configures which jobs are discovered by GetJobPaths.
 type JobPathOpts struct {
-	Root       string
-	IgnoreList []string
+	Root         string
+	IgnoreList   []string
+	MavenModules bool
 }
 
 // Parse loads the configuration for the job from disk and marshals it into a Job object.
@@ -37,16 +42,42 @@ func (jp JobPath) Parse() (Job, error) {
 	return job, err
 }
 
+// JobTree is what WalkJobs finds in the jobs folders besides the job paths.
+type JobTree struct {
+	// Items are ordered by full name.
+	Items     []Item
+	Indexings []Indexing
+}
+
 // GetJobPaths recursively searches a given folder for jobs and puts the JobPaths associated with the discovered jobs on the resultChan channel.
 func GetJobPaths(opts JobPathOpts, resultChan chan<- JobPath) error {
+	_, err := WalkJobs(opts, resultChan)
+	return err
+}
+
+// WalkJobs does the same as GetJobPaths, and returns the items and the indexings it came across, so the jobs folders
+// are only walked once. The JobTree holds what was found before an error.
+func WalkJobs(opts JobPathOpts, resultChan chan<- JobPath) (JobTree, error) {
 	defer close(resultChan)
 
-	err := parseJobFolder(opts.Root, opts, resultChan)
-	if err != nil {
-		return err
-	}
+	var tree JobTree
+	err := parseJobFolder(opts.Root, opts, resultChan, &tree)
 
-	return nil
+	sort.Slice(tree.Items, func(i, j int) bool {
+		return tree.Items[i].FullName() < tree.Items[j].FullName()
+	})
+
+	return tree, err
+}
+
+// walkJobTree walks the jobs folders for the items and indexings only.
+func walkJobTree(opts JobPathOpts) (JobTree, error) {
+	jobPaths := make(chan JobPath)
+	go func() {
+		for range jobPaths {
+		}
+	}()
+	return WalkJobs(opts, jobPaths)
 }
 
 func contains(needle string, haystack []string) bool {
@@ -58,27 +89,82 @@ func contains(needle string, haystack []string) bool {
 	return false
 }
 
-func parseJobFolder(path string, opts JobPathOpts, resultChan chan<- JobPath) error {
+func parseJobFolder(path string, opts JobPathOpts, resultChan chan<- JobPath, tree *JobTree) error {
 
 	if contains(filepath.Base(path), opts.IgnoreList) {
 		return nil
 	}
 
-	childErr := parseChildJobs(path, opts, resultChan)
-	buildErr := parseBuildPath(path, resultChan)
+	if path != opts.Root {
+		parseItem(path, tree)
+	}
+
+	childErr := parseChildJobs(path, opts, resultChan, tree)
+	// the Jenkins folder itself is never a job, even when it holds a custom builds folder
+	buildErr := fmt.Errorf("%s is the Jenkins folder", path)
+	if path != opts.Root {
+		buildErr = parseBuildPath(path, resultChan)
+	}
+
+	err := parseBranchJobs(path, opts, resultChan)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	err = parseMatrixConfigurations(path, resultChan)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
 
-	if childErr != nil && buildErr != nil {
+	if opts.MavenModules {
+		err = parseMavenModules(path, resultChan)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	if childErr != nil && buildErr != nil && path == opts.Root {
 		// Check if config.xml file exists, if so it's an empty Jenkins folder, which we don't care about
 		_, err := os.Stat(filepath.Join(path, "config.xml"))
 		if err != nil {
 			return fmt.Errorf("parsing paths failed: %v, %v", childErr, buildErr)
 		}
 	}
+	// job folders without builds, jobs or configuration are left behind by jobs that were deleted or renamed, which
+	// FindStaleJobDirs reports, so they don't stop the walk
 
 	return nil
 }
 
-func parseChildJobs(path string, opts JobPathOpts, resultChan chan<- JobPath) error {
+// parseItem adds the item and the indexing of the job folder at path to the tree. Configurations and indexings that
+// can't be parsed are logged and skipped, so they don't hide the other items.
+func parseItem(path string, tree *JobTree) {
+	config, err := parseJobConfig(path)
+	if err == nil {
+		item := Item{Config: config}
+		item.Folder, item.Name = splitJobName(path)
+		tree.Items = append(tree.Items, item)
+	} else if !os.IsNotExist(err) {
+		log.Warnf("couldn't parse configuration of %s: %v", path, err)
+	}
+
+	for _, indexingFile := range indexingFiles {
+		indexing, err := parseIndexing(filepath.Join(path, indexingFile))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			log.Warnf("couldn't parse indexing of %s: %v", path, err)
+			break
+		}
+
+		indexing.Folder, indexing.Name = splitJobName(path)
+		tree.Indexings = append(tree.Indexings, indexing)
+		break
+	}
+}
+
+func parseChildJobs(path string, opts JobPathOpts, resultChan chan<- JobPath, tree *JobTree) error {
 	jobsPath := filepath.Join(path, "jobs")
 
 	_, err := os.Stat(jobsPath)
@@ -96,7 +182,7 @@ func parseChildJobs(path string, opts JobPathOpts, resultChan chan<- JobPath) er
 			continue
 		}
 
-		err = parseJobFolder(filepath.Join(path, "jobs", jobDir.Name()), opts, resultChan)
+		err = parseJobFolder(filepath.Join(path, "jobs", jobDir.Name()), opts, resultChan, tree)
 		if err != nil {
 			return err
 		}
@@ -105,6 +191,32 @@ func parseChildJobs(path string, opts JobPathOpts, resultChan chan<- JobPath) er
 	return nil
 }
 
+// parseBranchJobs finds the branch jobs of a multibranch project, which are stored in branches/ instead of jobs/.
+func parseBranchJobs(path string, opts JobPathOpts, resultChan chan<- JobPath) error {
+	branchesPath := filepath.Join(path, "branches")
+
+	_, err := os.Stat(branchesPath)
+	if err != nil {
+		return err
+	}
+
+	branchDirs, err := ioutil.ReadDir(branchesPath)
+	if err != nil {
+		return err
+	}
+
+	for _, branchDir := range branchDirs {
+		if !branchDir.IsDir() || contains(branchDir.Name(), opts.IgnoreList) {
+			continue
+		}
+
+		// branches that were never built have no builds folder
+		parseBuildPath(filepath.Join(branchesPath, branchDir.Name()), resultChan)
+	}
+
+	return nil
+}
+
 func parseBuildPath(path string, resultChan chan<- JobPath) error {
 	buildsPath := filepath.Join(path, "builds")
 
@@ -117,3 +229,57 @@ func parseBuildPath(path string, resultChan chan<- JobPath) error {
 
 	return nil
 }
+
+// parseMatrixConfigurations finds the configurations of a matrix project, which are stored as
+// configurations/axis-<name>/<value>/... with one level per axis.
+func parseMatrixConfigurations(path string, resultChan chan<- JobPath) error {
+	configurationsPath := filepath.Join(path, "configurations")
+
+	_, err := os.Stat(configurationsPath)
+	if err != nil {
+		return err
+	}
+
+	return filepath.Walk(configurationsPath, func(configurationPath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() {
+			return nil
+		}
+
+		err = parseBuildPath(configurationPath, resultChan)
+		if err == nil {
+			return filepath.SkipDir
+		}
+
+		return nil
+	})
+}
+
+// parseMavenModules finds the modules of a Maven project, which are stored as modules/<groupId>$<artifactId>.
+func parseMavenModules(path string, resultChan chan<- JobPath) error {
+	modulesPath := filepath.Join(path, "modules")
+
+	_, err := os.Stat(modulesPath)
+	if err != nil {
+		return err
+	}
+
+	moduleDirs, err := ioutil.ReadDir(modulesPath)
+	if err != nil {
+		return err
+	}
+
+	for _, moduleDir := range moduleDirs {
+		if !moduleDir.IsDir() {
+			continue
+		}
+
+		// modules that were never built have no builds folder
+		parseBuildPath(filepath.Join(modulesPath, moduleDir.Name()), resultChan)
+	}
+
+	return nil
+}
@@ -15,6 +15,8 @@ package jenkins
 
 import (
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -24,13 +26,21 @@ var (
 		"testdata/jobs/folder/jobs/failedjob",
 		"testdata/jobs/folder/jobs/folderjob",
 		"testdata/jobs/folder/jobs/jobwithoutbuilds",
+		"testdata/jobs/folder/jobs/pipelinejob",
+		"testdata/jobs/mavenjob",
+		"testdata/jobs/multibranch/branches/PR-42",
+		"testdata/jobs/multibranch/branches/feature-login.7ajqb1",
+		"testdata/jobs/multibranch/branches/main",
+		"testdata/jobs/matrixjob",
+		"testdata/jobs/matrixjob/configurations/axis-jdk/11/axis-os/linux",
+		"testdata/jobs/matrixjob/configurations/axis-jdk/17/axis-os/linux",
 	}
 )
 
 func TestGetJobPaths(t *testing.T) {
 	resultChan := make(chan JobPath)
 
-	go GetJobPaths("testdata", resultChan)
+	go GetJobPaths(JobPathOpts{Root: "testdata"}, resultChan)
 
 	i := 0
 
@@ -46,6 +56,66 @@ func TestGetJobPaths(t *testing.T) {
 	}
 }
 
+func TestWalkJobs(t *testing.T) {
+	resultChan := make(chan JobPath)
+	trees := make(chan JobTree, 1)
+	errs := make(chan error, 1)
+
+	go func() {
+		tree, err := WalkJobs(JobPathOpts{Root: "testdata"}, resultChan)
+		trees <- tree
+		errs <- err
+	}()
+
+	i := 0
+	for range resultChan {
+		i++
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	tree := <-trees
+
+	if i != len(paths) {
+		t.Errorf("found %d job paths, expected %d", i, len(paths))
+	}
+	if len(tree.Items) != 10 {
+		t.Errorf("found %d items, expected %d", len(tree.Items), 10)
+	}
+	if len(tree.Indexings) != 2 {
+		t.Errorf("found %d indexings, expected %d", len(tree.Indexings), 2)
+	}
+}
+
+func TestWalkJobsWithLeftoverFolder(t *testing.T) {
+	// jobs/broken has nothing but an indexing, like the folders left behind by deleted jobs
+	tree, err := walkJobTree(JobPathOpts{Root: filepath.Join("testdata", "malformedhome")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tree.Indexings) != 1 || tree.Indexings[0].Name != "multibranch" {
+		t.Errorf("indexings are %+v, expected only the indexing of multibranch", tree.Indexings)
+	}
+}
+
+func TestGetJobPathsWithMavenModules(t *testing.T) {
+	resultChan := make(chan JobPath)
+
+	go GetJobPaths(JobPathOpts{Root: "testdata", MavenModules: true}, resultChan)
+
+	modules := 0
+	for path := range resultChan {
+		if strings.Contains(string(path), "/modules/") {
+			modules++
+		}
+	}
+
+	if modules != 2 {
+		t.Errorf("%d Maven modules were present in results, expected %d", modules, 2)
+	}
+}
+
 func checkPath(path string) bool {
 	for _, p := range paths {
 		if p == path {
@@ -55,10 +125,27 @@ func checkPath(path string) bool {
 	return false
 }
 
+func TestGetJobPathsWithCustomBuildsDir(t *testing.T) {
+	resultChan := make(chan JobPath)
+
+	go GetJobPaths(JobPathOpts{Root: filepath.Join("testdata", "stalehome")}, resultChan)
+
+	var paths []string
+	for path := range resultChan {
+		paths = append(paths, string(path))
+	}
+
+	// the builds folder in the Jenkins folder doesn't make it a job
+	expected := []string{filepath.Join("testdata", "stalehome", "jobs", "folder", "jobs", "renamedjob")}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("job paths are %v, expected %v", paths, expected)
+	}
+}
+
 func TestNonExistentPath(t *testing.T) {
 	resultChan := make(chan JobPath)
 
-	err := GetJobPaths(filepath.Join("testdata", "foobar"), resultChan)
+	err := GetJobPaths(JobPathOpts{Root: filepath.Join("testdata", "foobar")}, resultChan)
 	if err == nil {
 		t.Error("non existent path should return an error")
 	}
@@ -0,0 +1,206 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/log"
+)
+
+// BuiltInNode is the name used for the built-in node of the controller, which builds record as an empty builtOn.
+const BuiltInNode = "built-in"
+
+// launcherTypes maps the launcher classes of agents to the type reported in Node. Unknown classes are used as the type as is.
+var launcherTypes = map[string]string{
+	"hudson.plugins.sshslaves.SSHLauncher": "ssh",
+	"hudson.slaves.JNLPLauncher":           "inbound",
+	"hudson.slaves.CommandLauncher":        "command",
+}
+
+// retentionStrategies maps the retention strategy classes of agents to the strategy reported in Node.
+// Unknown classes are used as the strategy as is.
+var retentionStrategies = map[string]string{
+	"hudson.slaves.RetentionStrategy$Always":         "always",
+	"hudson.slaves.RetentionStrategy$Demand":         "demand",
+	"hudson.slaves.SimpleScheduledRetentionStrategy": "scheduled",
+}
+
+// Node represents a permanent agent, as configured in the nodes folder of Jenkins.
+type Node struct {
+	raw                nodeXML
+	Name               string
+	Description        string
+	NumExecutors       int
+	Mode               string
+	Labels             []string
+	Launcher           string
+	RetentionStrategy  string
+	TemporarilyOffline bool
+}
+
+type nodeXML struct {
+	Name                  string    `xml:"name"`
+	Description           string    `xml:"description"`
+	NumExecutors          int       `xml:"numExecutors"`
+	Mode                  string    `xml:"mode"`
+	Label                 string    `xml:"label"`
+	Launcher              classXML  `xml:"launcher"`
+	RetentionStrategy     classXML  `xml:"retentionStrategy"`
+	TemporaryOfflineCause *classXML `xml:"temporaryOfflineCause"`
+	TemporarilyOffline    bool      `xml:"temporarilyOffline"`
+}
+
+// GetNodes returns the permanent agents in the nodes folder of the Jenkins folder at root, sorted by name.
+// Jenkins without agents doesn't have a nodes folder, so that results in no nodes instead of an error. Nodes whose
+// config.xml can't be parsed are logged and skipped.
+func GetNodes(root string) ([]Node, error) {
+	nodesPath := filepath.Join(root, "nodes")
+
+	dirs, err := ioutil.ReadDir(nodesPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []Node
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		node, err := parseNode(filepath.Join(nodesPath, dir.Name()))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			log.Warnf("couldn't parse node %s: %v", dir.Name(), err)
+			continue
+		}
+		nodes = append(nodes, node)
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+
+	return nodes, nil
+}
+
+func parseNode(path string) (Node, error) {
+	var node Node
+
+	byteValue, err := ioutil.ReadFile(filepath.Join(path, "config.xml"))
+	if err != nil {
+		return node, err
+	}
+
+	err = xml.Unmarshal(forceXMLVersion(byteValue), &node.raw)
+	if err != nil {
+		return node, err
+	}
+
+	node.Name = node.raw.Name
+	if node.Name == "" {
+		node.Name = filepath.Base(path)
+	}
+	node.Description = node.raw.Description
+	node.NumExecutors = node.raw.NumExecutors
+	node.Mode = node.raw.Mode
+	node.Labels = strings.Fields(node.raw.Label)
+	node.Launcher = classType(launcherTypes, node.raw.Launcher.Class)
+	node.RetentionStrategy = classType(retentionStrategies, node.raw.RetentionStrategy.Class)
+	// the offline cause replaced the temporarilyOffline flag of older configurations
+	node.TemporarilyOffline = node.raw.TemporaryOfflineCause != nil || node.raw.TemporarilyOffline
+
+	return node, nil
+}
+
+func classType(types map[string]string, class string) string {
+	if t, ok := types[class]; ok {
+		return t
+	}
+	return class
+}
+
+// NodeBuildStats aggregates the retained builds that ran on a node.
+type NodeBuildStats struct {
+	Node          string
+	Builds        map[string]int
+	BuildDuration time.Duration
+}
+
+// NodeAggregator builds the build statistics of all nodes from the jobs that are added to it.
+type NodeAggregator struct {
+	stats map[string]*NodeBuildStats
+	runs  map[string][]run
+}
+
+// NewNodeAggregator creates an instance of NodeAggregator.
+func NewNodeAggregator() *NodeAggregator {
+	return &NodeAggregator{
+		stats: make(map[string]*NodeBuildStats),
+		runs:  make(map[string][]run),
+	}
+}
+
+// Add adds the completed builds of the job to the statistics of the nodes they ran on, and both the completed and the
+// running builds to their utilisation. Pipelines don't record a node, since they allocate nodes in their steps, so they
+// are skipped.
+func (aggregator *NodeAggregator) Add(job Job) {
+	for _, build := range job.Builds {
+		if build.BuiltOn == "" {
+			continue
+		}
+
+		if !build.flyweight {
+			aggregator.runs[build.BuiltOn] = append(aggregator.runs[build.BuiltOn], newRun(build))
+		}
+
+		if build.Result == "" {
+			continue
+		}
+
+		stats, ok := aggregator.stats[build.BuiltOn]
+		if !ok {
+			stats = &NodeBuildStats{Node: build.BuiltOn, Builds: make(map[string]int)}
+			aggregator.stats[build.BuiltOn] = stats
+		}
+
+		stats.Builds[build.Result]++
+		stats.BuildDuration += time.Duration(build.Duration) * time.Millisecond
+	}
+}
+
+// Stats returns the build statistics of all nodes that ran builds, ordered by node. This includes the built-in node and
+// agents that aren't configured in the nodes folder, such as removed agents and agents of clouds.
+func (aggregator *NodeAggregator) Stats() []NodeBuildStats {
+	var stats []NodeBuildStats
+	for _, s := range aggregator.stats {
+		stats = append(stats, *s)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Node < stats[j].Node
+	})
+
+	return stats
+}
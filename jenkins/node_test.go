@@ -0,0 +1,127 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGetNodes(t *testing.T) {
+	nodes, err := GetNodes("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(nodes) != 2 {
+		t.Fatalf("found %d nodes, expected %d", len(nodes), 2)
+	}
+
+	expected := []Node{
+		{
+			Name:              "linux-agent",
+			Description:       "Linux build agent",
+			NumExecutors:      2,
+			Mode:              "NORMAL",
+			Labels:            []string{"linux", "docker"},
+			Launcher:          "ssh",
+			RetentionStrategy: "always",
+		},
+		{
+			Name:               "windows-agent",
+			NumExecutors:       1,
+			Mode:               "EXCLUSIVE",
+			Labels:             []string{"windows"},
+			Launcher:           "inbound",
+			RetentionStrategy:  "demand",
+			TemporarilyOffline: true,
+		},
+	}
+
+	for i, node := range nodes {
+		node.raw = nodeXML{}
+		if !reflect.DeepEqual(node, expected[i]) {
+			t.Errorf("node is %+v, expected %+v", node, expected[i])
+		}
+	}
+}
+
+func TestGetNodesWithoutNodesFolder(t *testing.T) {
+	nodes, err := GetNodes("testdata/jobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(nodes) != 0 {
+		t.Errorf("found %d nodes, expected none", len(nodes))
+	}
+}
+
+func TestGetNodesMalformed(t *testing.T) {
+	nodes, err := GetNodes(filepath.Join("testdata", "malformedhome"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(nodes) != 1 || nodes[0].Name != "linux-agent" {
+		t.Errorf("found nodes %+v, expected only linux-agent", nodes)
+	}
+}
+
+func TestBuiltOn(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"testdata/jobs/rootjob/builds/1/build.xml", BuiltInNode},
+		{"testdata/jobs/matrixjob/configurations/axis-jdk/11/axis-os/linux/builds/1/build.xml", "linux-agent"},
+		{"testdata/jobs/folder/jobs/pipelinejob/builds/2/build.xml", ""},
+	}
+
+	for _, test := range tests {
+		build, err := newBuildFromXML(test.path)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if build.BuiltOn != test.expected {
+			t.Errorf("build %s was built on %q, expected %q", test.path, build.BuiltOn, test.expected)
+		}
+	}
+}
+
+func TestNodeAggregator(t *testing.T) {
+	job := Job{Builds: []Build{
+		{Number: 4, BuiltOn: "linux-agent"},
+		{Number: 3, BuiltOn: "linux-agent", Result: "FAILURE", Duration: 30000},
+		{Number: 2, BuiltOn: "linux-agent", Result: "SUCCESS", Duration: 60000},
+		{Number: 1, BuiltOn: BuiltInNode, Result: "SUCCESS", Duration: 1000},
+	}}
+	pipeline := Job{Builds: []Build{{Number: 1, Result: "SUCCESS", Duration: 5000}}}
+
+	aggregator := NewNodeAggregator()
+	aggregator.Add(job)
+	aggregator.Add(pipeline)
+
+	expected := []NodeBuildStats{
+		{Node: BuiltInNode, Builds: map[string]int{"SUCCESS": 1}, BuildDuration: time.Second},
+		{Node: "linux-agent", Builds: map[string]int{"FAILURE": 1, "SUCCESS": 1}, BuildDuration: 90 * time.Second},
+	}
+
+	if stats := aggregator.Stats(); !reflect.DeepEqual(stats, expected) {
+		t.Errorf("node stats are %+v, expected %+v", stats, expected)
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+)
+
+// PasswordParameter is the Parameter.Type of password parameters.
+const PasswordParameter = "password"
+
+// Parameter is a build parameter defined by a job.
+type Parameter struct {
+	Name        string
+	Type        string
+	Description string
+	// Default is the default value of the parameter. It is never set for password parameters, HasDefault tells
+	// whether they have one. The default of a choice parameter is its first choice.
+	Default    string
+	HasDefault bool
+}
+
+type parameterDefinitionsXML struct {
+	Parameters []Parameter `xml:",any"`
+}
+
+type choicesXML struct {
+	Array []string `xml:"a>string"`
+	List  []string `xml:"string"`
+}
+
+// parameterType turns the class of a parameter definition into its type, e.g. hudson.model.StringParameterDefinition
+// becomes string.
+func parameterType(class string) string {
+	class = class[strings.LastIndex(class, ".")+1:]
+	return strings.ToLower(strings.TrimSuffix(class, "ParameterDefinition"))
+}
+
+// UnmarshalXML decodes a parameter definition. The default value of password parameters is skipped while decoding,
+// so the secret never ends up in the parsed configuration.
+func (parameter *Parameter) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	parameter.Type = parameterType(start.Name.Local)
+
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.EndElement:
+			return nil
+		case xml.StartElement:
+			switch {
+			case t.Name.Local == "defaultValue" && parameter.Type == PasswordParameter:
+				parameter.HasDefault, err = hasContent(d)
+			case t.Name.Local == "defaultValue":
+				err = d.DecodeElement(&parameter.Default, &t)
+				parameter.HasDefault = parameter.Default != ""
+			case t.Name.Local == "name":
+				err = d.DecodeElement(&parameter.Name, &t)
+			case t.Name.Local == "description":
+				err = d.DecodeElement(&parameter.Description, &t)
+			case t.Name.Local == "choices":
+				var choices choicesXML
+				err = d.DecodeElement(&choices, &t)
+				if all := append(choices.Array, choices.List...); len(all) > 0 {
+					parameter.Default, parameter.HasDefault = all[0], true
+				}
+			default:
+				err = d.Skip()
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// hasContent consumes the current element and reports whether it holds anything but whitespace, without keeping it.
+func hasContent(d *xml.Decoder) (bool, error) {
+	content := false
+
+	for depth := 1; depth > 0; {
+		token, err := d.Token()
+		if err != nil {
+			return content, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			content = true
+			depth++
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) > 0 {
+				content = true
+			}
+		}
+	}
+
+	return content, nil
+}
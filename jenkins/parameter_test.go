@@ -0,0 +1,84 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParameters(t *testing.T) {
+	config, err := parseJobConfig(pipelineJob)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Parameter{
+		{Name: "ENVIRONMENT", Type: "string", Description: "Environment to deploy to", Default: "staging", HasDefault: true},
+		{Name: "DEPLOY_TOKEN", Type: PasswordParameter, HasDefault: true},
+		{Name: "DRY_RUN", Type: "boolean", Default: "true", HasDefault: true},
+		{Name: "REGION", Type: "choice", Default: "eu-west-1", HasDefault: true},
+		{Name: "SSH_KEY", Type: "credentials"},
+	}
+
+	if !reflect.DeepEqual(config.Parameters, expected) {
+		t.Errorf("config.Parameters is %+v, expected %+v", config.Parameters, expected)
+	}
+}
+
+func TestPasswordDefaultIsNotKept(t *testing.T) {
+	config, err := parseJobConfig(pipelineJob)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if parsed := fmt.Sprintf("%#v", config); strings.Contains(parsed, "AQAAABAAAAAQ") {
+		t.Error("the parsed configuration contains the default of the password parameter")
+	}
+}
+
+func TestPasswordWithoutDefault(t *testing.T) {
+	var parameter Parameter
+	err := xml.Unmarshal([]byte(`<hudson.model.PasswordParameterDefinition>
+  <name>TOKEN</name>
+  <defaultValue>  </defaultValue>
+</hudson.model.PasswordParameterDefinition>`), &parameter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Parameter{Name: "TOKEN", Type: PasswordParameter}
+	if parameter != expected {
+		t.Errorf("parameter is %+v, expected %+v", parameter, expected)
+	}
+}
+
+func TestParameterType(t *testing.T) {
+	tests := map[string]string{
+		"hudson.model.StringParameterDefinition":                                "string",
+		"hudson.model.TextParameterDefinition":                                  "text",
+		"hudson.model.FileParameterDefinition":                                  "file",
+		"net.uaznia.lukanus.hudson.plugins.gitparameter.GitParameterDefinition": "git",
+		"org.example.CustomParameter":                                           "customparameter",
+	}
+
+	for class, expected := range tests {
+		if parsed := parameterType(class); parsed != expected {
+			t.Errorf("type of %s is %s, expected %s", class, parsed, expected)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	stageStepDescriptor = "org.jenkinsci.plugins.workflow.support.steps.StageStep"
+	errorActionName     = "org.jenkinsci.plugins.workflow.actions.ErrorAction"
+	labelActionName     = "org.jenkinsci.plugins.workflow.actions.LabelAction"
+)
+
+// stepFunctions maps the descriptors of common pipeline steps to the function name used in a Jenkinsfile.
+// Steps that are not listed here are named after their descriptor class.
+var stepFunctions = map[string]string{
+	"org.jenkinsci.plugins.workflow.steps.durable_task.ShellStep":                "sh",
+	"org.jenkinsci.plugins.workflow.steps.durable_task.BatchScriptStep":          "bat",
+	"org.jenkinsci.plugins.workflow.steps.durable_task.PowershellScriptStep":     "powershell",
+	"org.jenkinsci.plugins.workflow.steps.durable_task.PowerShellCoreScriptStep": "pwsh",
+	"org.jenkinsci.plugins.workflow.steps.scm.GenericSCMStep":                    "checkout",
+	"org.jenkinsci.plugins.workflow.steps.scm.GitStep":                           "git",
+	"org.jenkinsci.plugins.workflow.steps.CoreStep":                              "step",
+	"org.jenkinsci.plugins.workflow.support.steps.build.BuildTriggerStep":        "build",
+	"org.jenkinsci.plugins.docker.workflow.WithDockerContainerStep":              "docker.inside",
+	"org.jenkinsci.plugins.docker.workflow.RegistryEndpointStep":                 "docker.withRegistry",
+}
+
+// PipelineFailure attributes a failed pipeline build to the stage and step that raised the error.
+type PipelineFailure struct {
+	Stage string
+	Step  string
+}
+
+type flowNodeXML struct {
+	Node    flowNodeDataXML    `xml:"node"`
+	Actions flowNodeActionsXML `xml:"actions"`
+}
+
+type flowNodeDataXML struct {
+	Class        string   `xml:"class,attr"`
+	ParentIDs    []string `xml:"parentIds>string"`
+	ID           string   `xml:"id"`
+	DescriptorID string   `xml:"descriptorId"`
+	StartID      string   `xml:"startId"`
+}
+
+type flowNodeActionsXML struct {
+	Actions []flowNodeActionXML `xml:",any"`
+}
+
+type flowNodeActionXML struct {
+	XMLName     xml.Name
+	DisplayName string `xml:"displayName"`
+}
+
+type flowNodeStoreXML struct {
+	Entries []flowNodeStoreEntryXML `xml:"entry"`
+}
+
+type flowNodeStoreEntryXML struct {
+	ID   string      `xml:"string"`
+	Node flowNodeXML `xml:"Tag"`
+}
+
+func (node *flowNodeXML) hasAction(name string) bool {
+	for _, a := range node.Actions.Actions {
+		if a.XMLName.Local == name {
+			return true
+		}
+	}
+	return false
+}
+
+// stageName returns the name of the stage if the node is the start of a stage body.
+func (node *flowNodeXML) stageName() string {
+	if !node.isBlockStart() || node.Node.DescriptorID != stageStepDescriptor {
+		return ""
+	}
+	for _, a := range node.Actions.Actions {
+		if a.XMLName.Local == labelActionName {
+			return a.DisplayName
+		}
+	}
+	return ""
+}
+
+func (node *flowNodeXML) isBlockStart() bool {
+	return strings.HasSuffix(node.Node.Class, "StepStartNode")
+}
+
+func (node *flowNodeXML) isBlockEnd() bool {
+	return strings.HasSuffix(node.Node.Class, "StepEndNode")
+}
+
+func (node *flowNodeXML) isAtom() bool {
+	return strings.HasSuffix(node.Node.Class, "StepAtomNode")
+}
+
+func (node *flowNodeXML) isFlowEnd() bool {
+	return strings.HasSuffix(node.Node.Class, "FlowEndNode")
+}
+
+// parseFlowNodes reads the flow graph of a pipeline build. Running builds keep one file per node in workflow/,
+// completed builds on newer Jenkins versions keep them all in workflow-completed/flowNodeStore.xml.
+func parseFlowNodes(buildPath string) (map[string]flowNodeXML, error) {
+	nodes := make(map[string]flowNodeXML)
+
+	storePath := filepath.Join(buildPath, "workflow-completed", "flowNodeStore.xml")
+	if _, err := os.Stat(storePath); err == nil {
+		byteValue, err := ioutil.ReadFile(storePath)
+		if err != nil {
+			return nodes, err
+		}
+
+		var store flowNodeStoreXML
+		err = xml.Unmarshal(forceXMLVersion(byteValue), &store)
+		if err != nil {
+			return nodes, err
+		}
+
+		for _, e := range store.Entries {
+			nodes[e.Node.Node.ID] = e.Node
+		}
+		return nodes, nil
+	}
+
+	nodeFiles, err := filepath.Glob(filepath.Join(buildPath, "workflow", "*.xml"))
+	if err != nil {
+		return nodes, err
+	}
+
+	for _, nodeFile := range nodeFiles {
+		byteValue, err := ioutil.ReadFile(nodeFile)
+		if err != nil {
+			return nodes, err
+		}
+
+		var node flowNodeXML
+		err = xml.Unmarshal(forceXMLVersion(byteValue), &node)
+		if err != nil {
+			return nodes, err
+		}
+		nodes[node.Node.ID] = node
+	}
+
+	return nodes, nil
+}
+
+// parsePipelineFailures walks the flow graph of a pipeline build and attributes every error to its stage and step.
+// Errors are propagated to all enclosing blocks, so only the nodes where an error originated are taken into account.
+func parsePipelineFailures(buildPath string) ([]PipelineFailure, error) {
+	var failures []PipelineFailure
+
+	nodes, err := parseFlowNodes(buildPath)
+	if err != nil || len(nodes) == 0 {
+		return failures, err
+	}
+
+	var origins []flowNodeXML
+	for _, node := range nodes {
+		if node.isAtom() && node.hasAction(errorActionName) {
+			origins = append(origins, node)
+		}
+	}
+
+	if len(origins) == 0 {
+		// The error was not raised by a step, attribute it to the innermost block that failed
+		var first *flowNodeXML
+		for id := range nodes {
+			node := nodes[id]
+			if node.isFlowEnd() || !node.hasAction(errorActionName) {
+				continue
+			}
+			if first == nil || nodeID(node) < nodeID(*first) {
+				first = &node
+			}
+		}
+		if first == nil {
+			return failures, nil
+		}
+		origins = append(origins, *first)
+	}
+
+	sort.Slice(origins, func(i, j int) bool {
+		return nodeID(origins[i]) < nodeID(origins[j])
+	})
+
+	seen := make(map[PipelineFailure]bool)
+	for _, origin := range origins {
+		failure := PipelineFailure{
+			Stage: enclosingStage(origin, nodes),
+			Step:  stepFunction(origin, nodes),
+		}
+		if seen[failure] {
+			continue
+		}
+		seen[failure] = true
+		failures = append(failures, failure)
+	}
+
+	return failures, nil
+}
+
+// enclosingStage returns the name of the innermost stage containing the node.
+// Walking up the parents passes previous siblings too, so blocks that were already closed are skipped entirely.
+func enclosingStage(node flowNodeXML, nodes map[string]flowNodeXML) string {
+	current := node
+	if current.isBlockEnd() {
+		start, ok := nodes[current.Node.StartID]
+		if !ok {
+			return ""
+		}
+		current = start
+		if stage := current.stageName(); stage != "" {
+			return stage
+		}
+	}
+
+	for len(current.Node.ParentIDs) > 0 {
+		parent, ok := nodes[current.Node.ParentIDs[0]]
+		if !ok {
+			return ""
+		}
+
+		if parent.isBlockEnd() {
+			start, ok := nodes[parent.Node.StartID]
+			if !ok {
+				return ""
+			}
+			current = start
+			continue
+		}
+
+		if stage := parent.stageName(); stage != "" {
+			return stage
+		}
+		current = parent
+	}
+
+	return ""
+}
+
+func stepFunction(node flowNodeXML, nodes map[string]flowNodeXML) string {
+	descriptor := node.Node.DescriptorID
+	if node.isBlockEnd() {
+		descriptor = nodes[node.Node.StartID].Node.DescriptorID
+	}
+
+	if descriptor == "" || descriptor == stageStepDescriptor {
+		return ""
+	}
+
+	if function, ok := stepFunctions[descriptor]; ok {
+		return function
+	}
+
+	class := descriptor[strings.LastIndex(descriptor, ".")+1:]
+	class = strings.TrimSuffix(class, "Step")
+	if class == "" {
+		return descriptor
+	}
+
+	runes := []rune(class)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+func nodeID(node flowNodeXML) int {
+	id, _ := strconv.Atoi(node.Node.ID)
+	return id
+}
@@ -0,0 +1,100 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import "testing"
+
+var (
+	pipelineJob    = "testdata/jobs/folder/jobs/pipelinejob"
+	malformedBuild = "testdata/malformed/builds/1"
+)
+
+func TestPipelineFailureFromNodeFiles(t *testing.T) {
+	build, err := parseBuild(pipelineJob + "/builds/lastFailedBuild")
+	if err != nil {
+		t.Fatal(err)
+	}
+	build.loadPipelineFailures()
+
+	if len(build.PipelineFailures) != 1 {
+		t.Fatalf("len(build.PipelineFailures) is %d, expected %d", len(build.PipelineFailures), 1)
+	}
+
+	expected := PipelineFailure{Stage: "Build", Step: "sh"}
+	if build.PipelineFailures[0] != expected {
+		t.Errorf("build.PipelineFailures[0] is %+v, expected %+v", build.PipelineFailures[0], expected)
+	}
+}
+
+func TestPipelineFailureFromNodeStore(t *testing.T) {
+	build, err := parseBuild(pipelineJob + "/builds/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	build.loadPipelineFailures()
+
+	if len(build.PipelineFailures) != 1 {
+		t.Fatalf("len(build.PipelineFailures) is %d, expected %d", len(build.PipelineFailures), 1)
+	}
+
+	expected := PipelineFailure{Stage: "Checkout", Step: "checkout"}
+	if build.PipelineFailures[0] != expected {
+		t.Errorf("build.PipelineFailures[0] is %+v, expected %+v", build.PipelineFailures[0], expected)
+	}
+}
+
+func TestPipelineSuccessHasNoFailures(t *testing.T) {
+	build, err := parseBuild(pipelineJob + "/builds/lastSuccessfulBuild")
+	if err != nil {
+		t.Fatal(err)
+	}
+	build.loadPipelineFailures()
+
+	if len(build.PipelineFailures) != 0 {
+		t.Errorf("build.PipelineFailures is %+v, expected none", build.PipelineFailures)
+	}
+}
+
+func TestStepFunction(t *testing.T) {
+	tests := map[string]string{
+		"org.jenkinsci.plugins.workflow.steps.durable_task.ShellStep":     "sh",
+		"org.jenkinsci.plugins.workflow.steps.scm.GenericSCMStep":         "checkout",
+		"org.jenkinsci.plugins.workflow.steps.ArtifactUnarchiverStepImpl": "artifactUnarchiverStepImpl",
+		"org.jenkinsci.plugins.workflow.steps.TimeoutStep":                "timeout",
+		stageStepDescriptor: "",
+	}
+
+	for descriptor, expected := range tests {
+		node := flowNodeXML{Node: flowNodeDataXML{Class: "cps.n.StepAtomNode", DescriptorID: descriptor}}
+		if function := stepFunction(node, nil); function != expected {
+			t.Errorf("stepFunction(%s) is %s, expected %s", descriptor, function, expected)
+		}
+	}
+}
+
+func TestMalformedFlowNodes(t *testing.T) {
+	build, err := parseBuild(malformedBuild)
+	if err != nil {
+		t.Fatal(err)
+	}
+	build.loadPipelineFailures()
+
+	if build.Result != "FAILURE" {
+		t.Errorf("build.Result is %s, expected %s", build.Result, "FAILURE")
+	}
+
+	if len(build.PipelineFailures) != 0 {
+		t.Errorf("build.PipelineFailures is %+v, expected none", build.PipelineFailures)
+	}
+}
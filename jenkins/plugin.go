@@ -0,0 +1,234 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"archive/zip"
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/common/log"
+)
+
+// pluginExtensions are the extensions of plugin archives, .hpi is the extension of older plugins.
+var pluginExtensions = []string{".jpi", ".hpi"}
+
+const manifestPath = "META-INF/MANIFEST.MF"
+
+// Plugin represents a plugin installed in the plugins folder of Jenkins.
+type Plugin struct {
+	ShortName           string
+	LongName            string
+	Version             string
+	ArchiveVersion      string
+	ExplodedVersion     string
+	RequiredCoreVersion string
+	Disabled            bool
+	Pinned              bool
+}
+
+// VersionMismatch returns whether the archive of the plugin and its exploded folder hold different versions.
+// Jenkins explodes the archive again on the next restart, so this usually means an update is waiting for a restart.
+func (p *Plugin) VersionMismatch() bool {
+	return p.ArchiveVersion != "" && p.ExplodedVersion != "" && p.ArchiveVersion != p.ExplodedVersion
+}
+
+// GetPlugins returns the plugins in the plugins folder of the Jenkins folder at root, sorted by short name.
+// Plugins that are only present as an archive or only as an exploded folder are included as well. Archives and
+// folders whose manifest can't be parsed are logged and skipped.
+func GetPlugins(root string) ([]Plugin, error) {
+	pluginsPath := filepath.Join(root, "plugins")
+
+	files, err := ioutil.ReadDir(pluginsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	plugins := make(map[string]*Plugin)
+	get := func(name string) *Plugin {
+		if plugins[name] == nil {
+			plugins[name] = &Plugin{}
+		}
+		return plugins[name]
+	}
+
+	for _, file := range files {
+		path := filepath.Join(pluginsPath, file.Name())
+
+		if file.IsDir() {
+			manifest, err := parseManifestFile(filepath.Join(path, filepath.FromSlash(manifestPath)))
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				log.Warnf("couldn't parse manifest of plugin %s: %v", file.Name(), err)
+				continue
+			}
+			plugin := get(file.Name())
+			plugin.ExplodedVersion = manifest["Plugin-Version"]
+			plugin.setManifest(manifest)
+			continue
+		}
+
+		name, ext := splitPluginFileName(file.Name())
+		switch ext {
+		case "":
+			continue
+		case ".disabled":
+			get(name).Disabled = true
+		case ".pinned":
+			get(name).Pinned = true
+		default:
+			manifest, err := parseArchiveManifest(path)
+			if err != nil {
+				// archives can be truncated by an interrupted download
+				log.Warnf("couldn't parse manifest of plugin %s: %v", file.Name(), err)
+				continue
+			}
+			plugin := get(name)
+			plugin.ArchiveVersion = manifest["Plugin-Version"]
+			plugin.setManifest(manifest)
+		}
+	}
+
+	var result []Plugin
+	for name, plugin := range plugins {
+		// marker files of plugins that were removed
+		if plugin.ArchiveVersion == "" && plugin.ExplodedVersion == "" {
+			continue
+		}
+		if plugin.ShortName == "" {
+			plugin.ShortName = name
+		}
+		// Jenkins loads the exploded folder
+		plugin.Version = plugin.ExplodedVersion
+		if plugin.Version == "" {
+			plugin.Version = plugin.ArchiveVersion
+		}
+		result = append(result, *plugin)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ShortName < result[j].ShortName
+	})
+
+	return result, nil
+}
+
+// splitPluginFileName returns the name of the plugin a file in the plugins folder belongs to, and whether it's an archive
+// (the extension of the archive), or a .disabled or .pinned marker. The extension is empty for all other files.
+func splitPluginFileName(fileName string) (string, string) {
+	for _, marker := range []string{".disabled", ".pinned"} {
+		if strings.HasSuffix(fileName, marker) {
+			name, ext := splitPluginFileName(strings.TrimSuffix(fileName, marker))
+			if ext == "" {
+				return fileName, ""
+			}
+			return name, marker
+		}
+	}
+
+	for _, ext := range pluginExtensions {
+		if strings.HasSuffix(fileName, ext) {
+			return strings.TrimSuffix(fileName, ext), ext
+		}
+	}
+
+	return fileName, ""
+}
+
+func (p *Plugin) setManifest(manifest map[string]string) {
+	if name := manifest["Short-Name"]; name != "" {
+		p.ShortName = name
+	} else if name := manifest["Extension-Name"]; name != "" {
+		p.ShortName = name
+	}
+	if name := manifest["Long-Name"]; name != "" {
+		p.LongName = name
+	}
+	// plugins built against Hudson declare their required core as Hudson-Version
+	if version := manifest["Jenkins-Version"]; version != "" {
+		p.RequiredCoreVersion = version
+	} else if version := manifest["Hudson-Version"]; version != "" {
+		p.RequiredCoreVersion = version
+	}
+}
+
+func parseManifestFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parseManifest(file)
+}
+
+func parseArchiveManifest(path string) (map[string]string, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	for _, file := range archive.File {
+		if file.Name != manifestPath {
+			continue
+		}
+		reader, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		return parseManifest(reader)
+	}
+
+	return nil, fmt.Errorf("archive doesn't contain %s", manifestPath)
+}
+
+// parseManifest parses the main section of a JAR manifest. Lines longer than 72 bytes are continued on the next line,
+// which starts with a single space.
+func parseManifest(r io.Reader) (map[string]string, error) {
+	manifest := make(map[string]string)
+
+	var key string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == "" {
+			// the main section ends at the first empty line
+			break
+		}
+		if strings.HasPrefix(line, " ") {
+			if key != "" {
+				manifest[key] += line[1:]
+			}
+			continue
+		}
+		parts := strings.SplitN(line, ": ", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid manifest line %q", line)
+		}
+		key = parts[0]
+		manifest[key] = parts[1]
+	}
+
+	return manifest, scanner.Err()
+}
@@ -0,0 +1,98 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGetPlugins(t *testing.T) {
+	expected := []Plugin{
+		{ShortName: "ant", LongName: "Ant Plugin", Version: "1.10 (private-08/23/2019 13:37-jenkins)", ExplodedVersion: "1.10 (private-08/23/2019 13:37-jenkins)", RequiredCoreVersion: "1.580.1"},
+		{ShortName: "credentials", LongName: "Credentials Plugin", Version: "2.3.0", ArchiveVersion: "2.3.0", RequiredCoreVersion: "2.138.4", Disabled: true},
+		{ShortName: "git", LongName: "Jenkins Git plugin", Version: "3.12.1", ArchiveVersion: "3.12.1", ExplodedVersion: "3.12.1", RequiredCoreVersion: "2.121.1"},
+		{ShortName: "workflow-job", LongName: "Pipeline: Job", Version: "2.35", ArchiveVersion: "2.36", ExplodedVersion: "2.35", RequiredCoreVersion: "2.150.3", Pinned: true},
+	}
+
+	plugins, err := GetPlugins("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(plugins, expected) {
+		t.Errorf("plugins are %+v, expected %+v", plugins, expected)
+	}
+
+	for _, plugin := range plugins {
+		mismatch := plugin.ShortName == "workflow-job"
+		if plugin.VersionMismatch() != mismatch {
+			t.Errorf("version mismatch of %s is %t, expected %t", plugin.ShortName, plugin.VersionMismatch(), mismatch)
+		}
+	}
+}
+
+func TestSplitPluginFileName(t *testing.T) {
+	tests := []struct {
+		fileName string
+		name     string
+		ext      string
+	}{
+		{"git.jpi", "git", ".jpi"},
+		{"credentials.hpi", "credentials", ".hpi"},
+		{"git.jpi.disabled", "git", ".disabled"},
+		{"git.hpi.pinned", "git", ".pinned"},
+		{"git.bak", "git.bak", ""},
+		{"something.disabled", "something.disabled", ""},
+	}
+
+	for _, test := range tests {
+		name, ext := splitPluginFileName(test.fileName)
+		if name != test.name || ext != test.ext {
+			t.Errorf("%s is split into %s and %q, expected %s and %q", test.fileName, name, ext, test.name, test.ext)
+		}
+	}
+}
+
+func TestParseManifest(t *testing.T) {
+	manifest, err := parseManifest(strings.NewReader("Manifest-Version: 1.0\r\nPlugin-Dependencies: workflow-scm-step:2.7,\r\n credentials:2.1.17\r\n\r\nName: ignored\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"Manifest-Version":    "1.0",
+		"Plugin-Dependencies": "workflow-scm-step:2.7,credentials:2.1.17",
+	}
+	if !reflect.DeepEqual(manifest, expected) {
+		t.Errorf("manifest is %v, expected %v", manifest, expected)
+	}
+}
+
+func TestGetPluginsMalformed(t *testing.T) {
+	expected := []Plugin{
+		{ShortName: "git", LongName: "Jenkins Git plugin", Version: "3.12.1", ArchiveVersion: "3.12.1", RequiredCoreVersion: "2.121.1"},
+	}
+
+	plugins, err := GetPlugins(filepath.Join("testdata", "malformedhome"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(plugins, expected) {
+		t.Errorf("plugins are %+v, expected %+v", plugins, expected)
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// queueStates maps the classes of the items in queue.xml to the state reported in QueueItem.
+var queueStates = map[string]string{
+	"hudson.model.Queue_-WaitingItem":   "waiting",
+	"hudson.model.Queue_-BlockedItem":   "blocked",
+	"hudson.model.Queue_-BuildableItem": "buildable",
+}
+
+// placeholderTask is the task of the node steps of pipelines, which wait in the queue for an executor on behalf of a build.
+const placeholderTask = "org.jenkinsci.plugins.workflow.support.steps.ExecutorStepExecution$PlaceholderTask"
+
+// Queue is the snapshot of the build queue that Jenkins saved in queue.xml. Jenkins only saves the queue when it
+// changes and when it shuts down, so ModTime tells how old the snapshot is.
+type Queue struct {
+	Items   []QueueItem
+	ModTime time.Time
+}
+
+// QueueItem is a task waiting in the queue.
+type QueueItem struct {
+	ID int
+	// Task is the full name of the job. The node steps of pipelines are reported as the job of their build.
+	Task string
+	// Job is the job of the task, with only its name set.
+	Job          Job
+	State        string
+	InQueueSince time.Time
+	// Causes are the classes of the causes of the item without their package, such as Cause$UserIdCause.
+	Causes []string
+}
+
+type queueXML struct {
+	Items queueItemsXML `xml:"items"`
+}
+
+type queueItemsXML struct {
+	Items []queueItemXML `xml:",any"`
+}
+
+type queueItemXML struct {
+	XMLName      xml.Name
+	ID           int             `xml:"id"`
+	Task         queueTaskXML    `xml:"task"`
+	InQueueSince int64           `xml:"inQueueSince"`
+	Causes       []causeEntryXML `xml:"actions>hudson.model.CauseAction>causeBag>entry"`
+}
+
+type queueTaskXML struct {
+	Class string `xml:"class,attr"`
+	Name  string `xml:",chardata"`
+	RunID string `xml:"runId"`
+}
+
+type causeEntryXML struct {
+	Elements []struct {
+		XMLName xml.Name
+	} `xml:",any"`
+}
+
+// ParseQueue parses the snapshot of the build queue in the Jenkins folder at root. Jenkins moves queue.xml out of the
+// way when it loads the queue at startup, so a missing file results in an error for which os.IsNotExist is true.
+func ParseQueue(root string) (Queue, error) {
+	var queue Queue
+
+	path := filepath.Join(root, "queue.xml")
+	info, err := os.Stat(path)
+	if err != nil {
+		return queue, err
+	}
+	queue.ModTime = info.ModTime()
+
+	byteValue, err := ioutil.ReadFile(path)
+	if err != nil {
+		return queue, err
+	}
+
+	var raw queueXML
+	err = xml.Unmarshal(forceXMLVersion(byteValue), &raw)
+	if err != nil {
+		return queue, err
+	}
+
+	for _, item := range raw.Items.Items {
+		state, ok := queueStates[item.XMLName.Local]
+		if !ok {
+			continue
+		}
+		task := item.Task.name()
+		queue.Items = append(queue.Items, QueueItem{
+			ID:           item.ID,
+			Task:         task,
+			Job:          queueJob(root, task),
+			State:        state,
+			InQueueSince: time.Unix(0, item.InQueueSince*int64(time.Millisecond)),
+			Causes:       item.causes(),
+		})
+	}
+
+	return queue, nil
+}
+
+// Stale returns whether the snapshot is older than maxAge, and so probably doesn't describe the current queue anymore.
+func (queue *Queue) Stale(now time.Time, maxAge time.Duration) bool {
+	return now.Sub(queue.ModTime) > maxAge
+}
+
+// Oldest returns the item that has been in the queue the longest.
+func (queue *Queue) Oldest() (QueueItem, bool) {
+	var oldest QueueItem
+	for i, item := range queue.Items {
+		if i == 0 || item.InQueueSince.Before(oldest.InQueueSince) {
+			oldest = item
+		}
+	}
+	return oldest, len(queue.Items) > 0
+}
+
+// queueJob returns the job with the given full name, with only its name set, so the task is labelled like the other
+// metrics of the job. The tasks of jobs that are gone from disk are split at their last /.
+func queueJob(root, fullName string) Job {
+	dir, ok := jobDir(root, fullName)
+	if !ok {
+		if i := strings.LastIndex(fullName, "/"); i != -1 {
+			return Job{Folder: fullName[:i], Name: fullName[i+1:]}
+		}
+		return Job{Folder: "/", Name: fullName}
+	}
+
+	job := Job{path: JobPath(dir)}
+	job.setName()
+	return Job{
+		Folder:        job.Folder,
+		Name:          job.Name,
+		Axes:          job.Axes,
+		Module:        job.Module,
+		Branch:        job.Branch,
+		IsPullRequest: job.IsPullRequest,
+	}
+}
+
+func (task *queueTaskXML) name() string {
+	if task.Class == placeholderTask {
+		// the run id is the full name of the job and the build number, separated by #
+		if i := strings.LastIndex(task.RunID, "#"); i != -1 {
+			return task.RunID[:i]
+		}
+		return task.RunID
+	}
+	return strings.TrimSpace(task.Name)
+}
+
+func (item *queueItemXML) causes() []string {
+	var causes []string
+	for _, entry := range item.Causes {
+		for _, element := range entry.Elements {
+			// the number of times the cause occurred
+			if element.XMLName.Local == "int" {
+				continue
+			}
+			class := strings.Replace(element.XMLName.Local, "_-", "$", -1)
+			causes = append(causes, class[strings.LastIndex(class, ".")+1:])
+		}
+	}
+	return causes
+}
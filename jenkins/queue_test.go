@@ -0,0 +1,100 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseQueue(t *testing.T) {
+	queue, err := ParseQueue("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat("testdata/queue.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !queue.ModTime.Equal(info.ModTime()) {
+		t.Errorf("queue.ModTime is %v, expected %v", queue.ModTime, info.ModTime())
+	}
+
+	since := func(ms int64) time.Time {
+		return time.Unix(0, ms*int64(time.Millisecond))
+	}
+	expected := []QueueItem{
+		{ID: 55, Task: "folder/pipelinejob", Job: Job{Folder: "folder", Name: "pipelinejob"}, State: "waiting", InQueueSince: since(1573203900000), Causes: []string{"TimerTrigger$TimerTriggerCause"}},
+		{ID: 53, Task: "rootjob", Job: Job{Folder: "/", Name: "rootjob"}, State: "blocked", InQueueSince: since(1573203600000), Causes: []string{"Cause$UserIdCause"}},
+		{ID: 54, Task: "folder/folderjob", Job: Job{Folder: "folder", Name: "folderjob"}, State: "buildable", InQueueSince: since(1573203700000), Causes: []string{"Cause$UpstreamCause"}},
+		{ID: 56, Task: "folder/pipelinejob", Job: Job{Folder: "folder", Name: "pipelinejob"}, State: "buildable", InQueueSince: since(1573203800000)},
+		{ID: 57, Task: "multibranch/feature%2Flogin", Job: Job{Folder: "/", Name: "multibranch", Branch: "feature/login"}, State: "waiting", InQueueSince: since(1573204000000)},
+	}
+
+	if !reflect.DeepEqual(queue.Items, expected) {
+		t.Errorf("queue items are %+v, expected %+v", queue.Items, expected)
+	}
+
+	oldest, ok := queue.Oldest()
+	if !ok || oldest.ID != 53 {
+		t.Errorf("oldest queue item is %d, expected %d", oldest.ID, 53)
+	}
+}
+
+func TestQueueJob(t *testing.T) {
+	tests := []struct {
+		fullName string
+		expected Job
+	}{
+		{"rootjob", Job{Folder: "/", Name: "rootjob"}},
+		{"matrixjob/jdk=11,os=linux", Job{Folder: "/", Name: "matrixjob", Axes: "jdk=11,os=linux"}},
+		{"multibranch/PR-42", Job{Folder: "/", Name: "multibranch", Branch: "PR-42", IsPullRequest: true}},
+		// jobs that are gone from disk
+		{"removedjob", Job{Folder: "/", Name: "removedjob"}},
+		{"folder/removed/job", Job{Folder: "folder/removed", Name: "job"}},
+	}
+
+	for _, test := range tests {
+		if job := queueJob("testdata", test.fullName); !reflect.DeepEqual(job, test.expected) {
+			t.Errorf("job of %s is %+v, expected %+v", test.fullName, job, test.expected)
+		}
+	}
+}
+
+func TestParseQueueMissing(t *testing.T) {
+	_, err := ParseQueue("testdata/jobs")
+	if !os.IsNotExist(err) {
+		t.Errorf("error is %v, expected the queue not to exist", err)
+	}
+}
+
+func TestQueueStale(t *testing.T) {
+	now := time.Date(2019, 11, 8, 12, 0, 0, 0, time.UTC)
+	queue := Queue{ModTime: now.Add(-2 * time.Hour)}
+
+	if !queue.Stale(now, time.Hour) {
+		t.Error("queue saved 2h ago is not stale, expected it to be stale after 1h")
+	}
+
+	if queue.Stale(now, 3*time.Hour) {
+		t.Error("queue saved 2h ago is stale, expected it to be current for 3h")
+	}
+
+	if _, ok := queue.Oldest(); ok {
+		t.Error("empty queue has an oldest item")
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"io/ioutil"
+	"strconv"
+)
+
+type buildsDirInfo struct {
+	count  int
+	newest int
+}
+
+// scanBuildsDir counts the build folders that are retained in the builds folder of a job and finds the number of the
+// newest one. Unlike parseBuilds, it includes builds without a usable build.xml, since they take up space as well. It
+// only reads the builds folder itself, the size of the builds is measured by the DiskScanner.
+func scanBuildsDir(buildsPath string) (buildsDirInfo, error) {
+	var info buildsDirInfo
+
+	buildDirs, err := ioutil.ReadDir(buildsPath)
+	if err != nil {
+		return info, err
+	}
+
+	for _, buildDir := range buildDirs {
+		if !buildDir.IsDir() {
+			continue
+		}
+		number, err := strconv.Atoi(buildDir.Name())
+		if err != nil {
+			continue
+		}
+
+		info.count++
+		if number > info.newest {
+			info.newest = number
+		}
+	}
+
+	return info, nil
+}
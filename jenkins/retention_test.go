@@ -0,0 +1,81 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestScanBuildsDir(t *testing.T) {
+	info, err := scanBuildsDir(filepath.Join(successfulJob, "builds"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.count != 1 {
+		t.Errorf("retained builds is %d, expected %d", info.count, 1)
+	}
+}
+
+func TestScanBuildsDirCountsBrokenBuilds(t *testing.T) {
+	info, err := scanBuildsDir(filepath.Join(pipelineJob, "builds"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.count != 3 {
+		t.Errorf("retained builds is %d, expected %d", info.count, 3)
+	}
+
+	if info.newest != 3 {
+		t.Errorf("newest build is %d, expected %d", info.newest, 3)
+	}
+}
+
+func TestBuildDiscarder(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected *BuildDiscarder
+		discards bool
+	}{
+		{successfulJob, &BuildDiscarder{DaysToKeep: -1, NumToKeep: 10, ArtifactDaysToKeep: -1, ArtifactNumToKeep: 3}, true},
+		{failedJob, &BuildDiscarder{DaysToKeep: 30, NumToKeep: -1, ArtifactDaysToKeep: -1, ArtifactNumToKeep: -1}, true},
+		{rootfolderJob, nil, false},
+	}
+
+	for _, test := range tests {
+		config, err := parseJobConfig(test.path)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if (config.BuildDiscarder == nil) != (test.expected == nil) || (test.expected != nil && *config.BuildDiscarder != *test.expected) {
+			t.Errorf("build discarder of %s is %+v, expected %+v", test.path, config.BuildDiscarder, test.expected)
+		}
+
+		if config.BuildDiscarder.DiscardsBuilds() != test.discards {
+			t.Errorf("DiscardsBuilds of %s is %t, expected %t", test.path, !test.discards, test.discards)
+		}
+	}
+}
+
+func TestArtifactOnlyDiscarder(t *testing.T) {
+	discarder := &BuildDiscarder{DaysToKeep: -1, NumToKeep: -1, ArtifactDaysToKeep: 7, ArtifactNumToKeep: -1}
+
+	if discarder.DiscardsBuilds() {
+		t.Error("a discarder that only removes artifacts discards builds")
+	}
+}
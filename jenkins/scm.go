@@ -0,0 +1,145 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// NoSCM is the SCMConfig.Type of jobs that don't check out a repository, such as pipelines with an inline script.
+const NoSCM = "none"
+
+// scmTypes maps the SCM classes and branch sources to the type reported in SCMConfig.
+// Unknown classes are used as the type as is.
+var scmTypes = map[string]string{
+	"hudson.scm.NullSCM":                                            NoSCM,
+	"hudson.plugins.git.GitSCM":                                     "git",
+	"hudson.scm.SubversionSCM":                                      "svn",
+	"jenkins.plugins.git.GitSCMSource":                              "git",
+	"org.jenkinsci.plugins.github_branch_source.GitHubSCMSource":    "github",
+	"org.jenkinsci.plugins.github_branch_source.GitHubSCMNavigator": "github",
+	"com.cloudbees.jenkins.plugins.bitbucket.BitbucketSCMSource":    "bitbucket",
+	"com.cloudbees.jenkins.plugins.bitbucket.BitbucketSCMNavigator": "bitbucket",
+}
+
+// SCMConfig describes where a job gets its sources from. Freestyle jobs and pipelines from SCM have an SCM, branches of
+// a multibranch project get the SCM of their branch, and multibranch projects and organization folders have branch
+// sources and navigators instead. Those discover the branches themselves, so they don't have branch specs.
+type SCMConfig struct {
+	Type         string
+	Repositories []string
+	BranchSpecs  []string
+}
+
+type scmXML struct {
+	Class         string   `xml:"class,attr"`
+	Remotes       []string `xml:"userRemoteConfigs>hudson.plugins.git.UserRemoteConfig>url"`
+	Branches      []string `xml:"branches>hudson.plugins.git.BranchSpec>name"`
+	Locations     []string `xml:"locations>hudson.scm.SubversionSCM_-ModuleLocation>remote"`
+	Remote        string   `xml:"remote"`
+	RepoOwner     string   `xml:"repoOwner"`
+	Repository    string   `xml:"repository"`
+	RepositoryURL string   `xml:"repositoryUrl"`
+	ServerURL     string   `xml:"serverUrl"`
+}
+
+type definitionXML struct {
+	Class string  `xml:"class,attr"`
+	SCM   *scmXML `xml:"scm"`
+}
+
+type navigatorsXML struct {
+	Navigators []navigatorXML `xml:",any"`
+}
+
+type navigatorXML struct {
+	XMLName xml.Name
+	scmXML
+}
+
+// parseSCM finds the SCM of the job in the first place it can be configured: the SCM of a freestyle job or a pipeline
+// from SCM, the SCM of a branch, the branch sources of a multibranch project or the navigators of an organization folder.
+func parseSCM(config *jobConfigXML) SCMConfig {
+	var scms []scmXML
+
+	switch {
+	case config.SCM != nil:
+		scms = append(scms, *config.SCM)
+	case config.Definition != nil && config.Definition.SCM != nil:
+		scms = append(scms, *config.Definition.SCM)
+	case config.BranchSCM != nil:
+		scms = append(scms, *config.BranchSCM)
+	case len(config.Sources) > 0:
+		scms = config.Sources
+	case len(config.Navigators.Navigators) > 0:
+		for _, navigator := range config.Navigators.Navigators {
+			// XStream escapes the underscores in class names that are used as element names
+			navigator.Class = strings.Replace(navigator.XMLName.Local, "__", "_", -1)
+			scms = append(scms, navigator.scmXML)
+		}
+	case config.Definition != nil:
+		// a pipeline definition without an SCM holds the script itself
+		return SCMConfig{Type: NoSCM}
+	default:
+		return SCMConfig{}
+	}
+
+	scm := SCMConfig{Type: scmType(scms[0].Class)}
+	for _, s := range scms {
+		scm.Repositories = append(scm.Repositories, s.repositories()...)
+		scm.BranchSpecs = append(scm.BranchSpecs, s.Branches...)
+	}
+
+	return scm
+}
+
+func scmType(class string) string {
+	if t, ok := scmTypes[class]; ok {
+		return t
+	}
+	return class
+}
+
+// repositories returns the URLs of the repositories of the SCM. The GitHub and Bitbucket branch sources only store
+// the owner and the name of the repository when they use the default server.
+func (scm scmXML) repositories() []string {
+	var repositories []string
+	repositories = append(repositories, scm.Remotes...)
+	repositories = append(repositories, scm.Locations...)
+
+	switch {
+	case scm.Remote != "":
+		repositories = append(repositories, scm.Remote)
+	case scm.RepositoryURL != "":
+		repositories = append(repositories, scm.RepositoryURL)
+	case scm.RepoOwner != "":
+		server := scm.ServerURL
+		if server == "" {
+			switch scmType(scm.Class) {
+			case "github":
+				server = "https://github.com"
+			case "bitbucket":
+				server = "https://bitbucket.org"
+			}
+		}
+		repository := strings.TrimSuffix(server, "/") + "/" + scm.RepoOwner
+		if scm.Repository != "" {
+			repository += "/" + scm.Repository
+		}
+		repositories = append(repositories, repository)
+	}
+
+	return repositories
+}
@@ -0,0 +1,65 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSCMConfig(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected SCMConfig
+	}{
+		{rootfolderJob, SCMConfig{Type: NoSCM}},
+		{successfulJob, SCMConfig{Type: "git", Repositories: []string{"https://git.example.com/team/library.git"}, BranchSpecs: []string{"*/master"}}},
+		{pipelineJob, SCMConfig{Type: "git", Repositories: []string{"https://git.example.com/team/app.git"}, BranchSpecs: []string{"*/main"}}},
+		{"testdata/jobs/mavenjob", SCMConfig{Type: "svn", Repositories: []string{"https://svn.example.com/repos/parent/trunk"}}},
+		{"testdata/jobs/multibranch", SCMConfig{Type: "git", Repositories: []string{"https://git.example.com/team/service.git"}}},
+		{"testdata/jobs/multibranch/branches/feature-login.7ajqb1", SCMConfig{Type: "git", Repositories: []string{"https://git.example.com/team/service.git"}, BranchSpecs: []string{"feature/login"}}},
+		{"testdata/jobs/organization", SCMConfig{Type: "github", Repositories: []string{"https://github.com/example"}}},
+		{folder, SCMConfig{}},
+	}
+
+	for _, test := range tests {
+		config, err := parseJobConfig(test.path)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if !reflect.DeepEqual(config.SCM, test.expected) {
+			t.Errorf("SCM of %s is %+v, expected %+v", test.path, config.SCM, test.expected)
+		}
+	}
+}
+
+func TestBranchSourceRepositories(t *testing.T) {
+	tests := []struct {
+		scm      scmXML
+		expected string
+	}{
+		{scmXML{Class: "org.jenkinsci.plugins.github_branch_source.GitHubSCMSource", RepoOwner: "example", Repository: "app"}, "https://github.com/example/app"},
+		{scmXML{Class: "org.jenkinsci.plugins.github_branch_source.GitHubSCMSource", RepoOwner: "example", Repository: "app", RepositoryURL: "https://github.com/example/app.git"}, "https://github.com/example/app.git"},
+		{scmXML{Class: "com.cloudbees.jenkins.plugins.bitbucket.BitbucketSCMSource", RepoOwner: "example", Repository: "app"}, "https://bitbucket.org/example/app"},
+		{scmXML{Class: "com.cloudbees.jenkins.plugins.bitbucket.BitbucketSCMSource", ServerURL: "https://bitbucket.example.com/", RepoOwner: "EX", Repository: "app"}, "https://bitbucket.example.com/EX/app"},
+	}
+
+	for _, test := range tests {
+		repositories := test.scm.repositories()
+		if len(repositories) != 1 || repositories[0] != test.expected {
+			t.Errorf("repositories of %+v are %v, expected [%s]", test.scm, repositories, test.expected)
+		}
+	}
+}
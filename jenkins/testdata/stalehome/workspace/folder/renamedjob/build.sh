@@ -0,0 +1 @@
+make
@@ -0,0 +1 @@
+removed
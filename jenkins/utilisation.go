@@ -0,0 +1,98 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"sort"
+	"time"
+)
+
+// newRun returns the time the build held an executor. Builds that are still running don't have a duration yet, their
+// run has no end.
+func newRun(build Build) run {
+	start := time.Unix(0, int64(build.StartTime)*int64(time.Millisecond))
+	if build.Result == "" {
+		return run{start: start}
+	}
+	return run{start, start.Add(time.Duration(build.Duration) * time.Millisecond)}
+}
+
+// NodeUtilisation describes how busy the executors of a node were during a window, reconstructed from the start time and
+// duration of the retained builds that ran on the node.
+type NodeUtilisation struct {
+	Node   string
+	Window time.Duration
+	// BusyTime is the total time the executors of the node were running builds during the window.
+	BusyTime time.Duration
+	// PeakConcurrency is the largest number of builds that ran on the node at the same time during the window.
+	PeakConcurrency int
+}
+
+// Ratio returns the fraction of the capacity of the given number of executors that was used during the window.
+func (u NodeUtilisation) Ratio(executors int) float64 {
+	if executors <= 0 || u.Window <= 0 {
+		return 0
+	}
+	return u.BusyTime.Seconds() / (float64(executors) * u.Window.Seconds())
+}
+
+// Utilisation reconstructs the utilisation of the node during the window that ends at end. Builds that are still running
+// hold their executor until end. Builds that only coordinate other builds, like the parent of matrix configurations,
+// don't take an executor and are skipped.
+func (aggregator *NodeAggregator) Utilisation(node string, end time.Time, window time.Duration) NodeUtilisation {
+	utilisation := NodeUtilisation{Node: node, Window: window}
+	start := end.Add(-window)
+
+	type event struct {
+		at    time.Time
+		delta int
+	}
+	var events []event
+
+	for _, r := range aggregator.runs[node] {
+		if r.end.IsZero() {
+			r.end = end
+		}
+		if !r.end.After(start) || !r.start.Before(end) {
+			continue
+		}
+		from, to := r.start, r.end
+		if from.Before(start) {
+			from = start
+		}
+		if to.After(end) {
+			to = end
+		}
+		utilisation.BusyTime += to.Sub(from)
+		events = append(events, event{from, 1}, event{to, -1})
+	}
+
+	// builds that end when another one starts don't run at the same time
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].at.Equal(events[j].at) {
+			return events[i].delta < events[j].delta
+		}
+		return events[i].at.Before(events[j].at)
+	})
+
+	concurrency := 0
+	for _, e := range events {
+		concurrency += e.delta
+		if concurrency > utilisation.PeakConcurrency {
+			utilisation.PeakConcurrency = concurrency
+		}
+	}
+
+	return utilisation
+}
@@ -0,0 +1,129 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUtilisation(t *testing.T) {
+	end := time.Date(2019, 11, 8, 12, 0, 0, 0, time.UTC)
+	startedAt := func(before time.Duration) int {
+		return int(end.Add(-before).UnixNano() / int64(time.Millisecond))
+	}
+	minutes := func(m int) int {
+		return m * 60 * 1000
+	}
+
+	job := Job{Builds: []Build{
+		// still running at the end of the window, only counts until the end
+		{Number: 6, BuiltOn: "linux-agent", Result: "SUCCESS", StartTime: startedAt(10 * time.Minute), Duration: minutes(20)},
+		// starts when build 4 ends
+		{Number: 5, BuiltOn: "linux-agent", Result: "SUCCESS", StartTime: startedAt(30 * time.Minute), Duration: minutes(15)},
+		{Number: 4, BuiltOn: "linux-agent", Result: "FAILURE", StartTime: startedAt(40 * time.Minute), Duration: minutes(10)},
+		// started before the window
+		{Number: 3, BuiltOn: "linux-agent", Result: "SUCCESS", StartTime: startedAt(70 * time.Minute), Duration: minutes(40)},
+		// outside the window
+		{Number: 2, BuiltOn: "linux-agent", Result: "SUCCESS", StartTime: startedAt(3 * time.Hour), Duration: minutes(30)},
+		{Number: 1, BuiltOn: "linux-agent", Result: "SUCCESS", StartTime: startedAt(50 * time.Minute), Duration: minutes(45), flyweight: true},
+	}}
+
+	aggregator := NewNodeAggregator()
+	aggregator.Add(job)
+
+	utilisation := aggregator.Utilisation("linux-agent", end, time.Hour)
+
+	// 10 + 15 + 10 + 30 minutes
+	if utilisation.BusyTime != 65*time.Minute {
+		t.Errorf("busy time is %v, expected %v", utilisation.BusyTime, 65*time.Minute)
+	}
+
+	if utilisation.PeakConcurrency != 2 {
+		t.Errorf("peak concurrency is %d, expected %d", utilisation.PeakConcurrency, 2)
+	}
+
+	if ratio := utilisation.Ratio(2); ratio < 0.54 || ratio > 0.55 {
+		t.Errorf("utilisation ratio of 2 executors is %f, expected %f", ratio, 65.0/120)
+	}
+
+	if ratio := utilisation.Ratio(0); ratio != 0 {
+		t.Errorf("utilisation ratio without executors is %f, expected 0", ratio)
+	}
+}
+
+func TestUtilisationOfRunningBuilds(t *testing.T) {
+	end := time.Date(2019, 11, 8, 12, 0, 0, 0, time.UTC)
+	startedAt := func(before time.Duration) int {
+		return int(end.Add(-before).UnixNano() / int64(time.Millisecond))
+	}
+
+	job := Job{Builds: []Build{
+		// running builds have no result and no duration yet
+		{Number: 3, BuiltOn: "linux-agent", StartTime: startedAt(20 * time.Minute)},
+		{Number: 2, BuiltOn: "linux-agent", StartTime: startedAt(2 * time.Hour)},
+		{Number: 1, BuiltOn: "linux-agent", Result: "SUCCESS", StartTime: startedAt(3 * time.Hour), Duration: 30 * 60 * 1000},
+	}}
+
+	aggregator := NewNodeAggregator()
+	aggregator.Add(job)
+
+	utilisation := aggregator.Utilisation("linux-agent", end, time.Hour)
+
+	// 20 minutes of build 3 and the whole window of build 2
+	if utilisation.BusyTime != 80*time.Minute {
+		t.Errorf("busy time is %v, expected %v", utilisation.BusyTime, 80*time.Minute)
+	}
+
+	if utilisation.PeakConcurrency != 2 {
+		t.Errorf("peak concurrency is %d, expected %d", utilisation.PeakConcurrency, 2)
+	}
+
+	// only completed builds are counted in the build statistics
+	stats := aggregator.Stats()
+	if len(stats) != 1 || stats[0].Builds["SUCCESS"] != 1 || len(stats[0].Builds) != 1 {
+		t.Errorf("build statistics are %+v, expected 1 successful build", stats)
+	}
+}
+
+func TestUtilisationOfIdleNode(t *testing.T) {
+	utilisation := NewNodeAggregator().Utilisation("windows-agent", time.Now(), time.Hour)
+
+	if utilisation.BusyTime != 0 || utilisation.PeakConcurrency != 0 {
+		t.Errorf("utilisation of an idle node is %+v, expected none", utilisation)
+	}
+}
+
+func TestFlyweightBuilds(t *testing.T) {
+	tests := []struct {
+		path      string
+		flyweight bool
+	}{
+		{"testdata/jobs/matrixjob/builds/1/build.xml", true},
+		{"testdata/jobs/matrixjob/configurations/axis-jdk/11/axis-os/linux/builds/1/build.xml", false},
+		{"testdata/jobs/mavenjob/builds/1/build.xml", false},
+		{"testdata/jobs/mavenjob/modules/com.example$app/builds/1/build.xml", true},
+	}
+
+	for _, test := range tests {
+		build, err := newBuildFromXML(test.path)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if build.flyweight != test.flyweight {
+			t.Errorf("build %s is flyweight: %t, expected %t", test.path, build.flyweight, test.flyweight)
+		}
+	}
+}
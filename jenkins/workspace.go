@@ -0,0 +1,319 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/common/log"
+)
+
+const itemFullName = "${ITEM_FULL_NAME}"
+
+// workspaceIndex is the file in which the workspace locator of the Branch API plugin keeps track of the folders it
+// assigned to the workspaces of branches, since their full names can be too long to use as a path.
+const workspaceIndex = "workspaces.txt"
+
+// Workspace is a workspace folder on the built-in node.
+type Workspace struct {
+	Path string
+	// Job is the full name of the job the workspace belongs to, as far as it can be told from the path.
+	Job   string
+	Bytes int64
+}
+
+// StaleJobDir is a folder in the jobs folder of Jenkins, or of one of its folders, without a config.xml. Jenkins doesn't
+// load it as a job, so it's left over from a job that was deleted or renamed while some of its files were in use, or
+// was put there by hand.
+type StaleJobDir struct {
+	Path string
+	// Job is the full name the job would have.
+	Job   string
+	Bytes int64
+}
+
+// FindOrphanedWorkspaces returns the workspaces on the built-in node of the Jenkins folder at root that don't belong to
+// any of its jobs, sorted by size with the largest first. Jobs are found by their config.xml, so jobs that were never
+// built and jobs whose builds are kept in a custom builds folder count as well. The sibling folders Jenkins creates
+// next to a workspace, like @tmp, @script and @2 for concurrent builds, belong to the job of the workspace. It looks at
+// no more than filesPerSecond files per second while measuring the size of the workspaces, or at as many as it can when
+// filesPerSecond is 0.
+func FindOrphanedWorkspaces(root string, filesPerSecond int) ([]Workspace, error) {
+	return findOrphanedWorkspaces(root, newRateLimiter(filesPerSecond))
+}
+
+func findOrphanedWorkspaces(root string, limiter *rateLimiter) ([]Workspace, error) {
+	jobs, folders, err := jobFullNames(root)
+	if err != nil {
+		return nil, err
+	}
+
+	workspaceDir := DefaultWorkspaceDir
+	if controller, err := ParseController(root); err == nil {
+		workspaceDir = controller.WorkspaceDir
+	}
+
+	var orphans []Workspace
+	for _, workspaceRoot := range workspaceRoots(root, workspaceDir) {
+		located, err := parseWorkspaceIndex(filepath.Join(workspaceRoot, workspaceIndex))
+		if err != nil && !os.IsNotExist(err) {
+			// without the index, the workspaces of all branches would look orphaned
+			log.Warnf("couldn't parse the workspace index of %s: %v", workspaceRoot, err)
+			continue
+		}
+
+		orphans = append(orphans, findOrphans(workspaceRoot, "", jobs, folders, located)...)
+	}
+
+	for i := range orphans {
+		orphans[i].Bytes = limitedDiskUsage(orphans[i].Path, limiter)
+	}
+
+	sort.SliceStable(orphans, func(i, j int) bool {
+		return orphans[i].Bytes > orphans[j].Bytes
+	})
+
+	return orphans, nil
+}
+
+// jobFullNames returns the full names of all jobs and of the branches and Maven modules in them, which can have
+// workspaces of their own, and the full names of all folders that contain jobs. Jobs in folders on the ignore list of
+// the collector are included, since their workspaces aren't orphaned.
+func jobFullNames(root string) (map[string]bool, map[string]bool, error) {
+	jobs := make(map[string]bool)
+	folders := make(map[string]bool)
+
+	items, err := GetItems(JobPathOpts{Root: root})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, item := range items {
+		name := item.FullName()
+		if folderTypes[item.Config.Type] {
+			folders[name] = true
+			continue
+		}
+
+		addJobName(name, jobs, folders)
+		for _, subJob := range subJobNames(itemDir(root, name), name) {
+			addJobName(subJob, jobs, folders)
+		}
+	}
+
+	return jobs, folders, nil
+}
+
+// folderTypes are the types of items that only hold other items, and never have a workspace.
+var folderTypes = map[string]bool{
+	"folder":              true,
+	"organization-folder": true,
+}
+
+// addJobName adds the job with the given full name to jobs, and the folders it's in to folders. The Jenkins folder
+// itself is never a job or a folder.
+func addJobName(name string, jobs, folders map[string]bool) {
+	name = strings.Trim(name, "/")
+	if name == "" {
+		return
+	}
+
+	jobs[name] = true
+	for folder := path.Dir(name); folder != "." && folder != "/"; folder = path.Dir(folder) {
+		folders[folder] = true
+	}
+}
+
+// itemDir returns the folder of the item with the given full name, which GetItems only finds in jobs folders.
+func itemDir(root, fullName string) string {
+	return filepath.Join(root, "jobs", filepath.FromSlash(strings.Replace(fullName, "/", "/jobs/", -1)))
+}
+
+// subJobNames returns the full names of the branches and Maven modules of the job at path. Matrix configurations are
+// built in the workspace of their parent, so they aren't included.
+func subJobNames(path, fullName string) []string {
+	var names []string
+
+	branchConfigs, _ := filepath.Glob(filepath.Join(path, "branches", "*", "config.xml"))
+	for _, config := range branchConfigs {
+		branch, _ := parseBranch(filepath.Dir(config))
+		names = append(names, fullName+"/"+url.PathEscape(branch))
+	}
+
+	moduleConfigs, _ := filepath.Glob(filepath.Join(path, "modules", "*", "config.xml"))
+	for _, config := range moduleConfigs {
+		names = append(names, fullName+"/"+filepath.Base(filepath.Dir(config)))
+	}
+
+	return names
+}
+
+// workspaceRoots returns the folders in which the built-in node keeps the workspaces of jobs: the workspace folder in
+// the Jenkins folder, and the folder of the configured workspaceDir if it contains the full names of the jobs.
+// Workspaces in the folders of the jobs themselves are removed together with their job, so they are never orphaned.
+func workspaceRoots(root, workspaceDir string) []string {
+	roots := []string{filepath.Join(root, "workspace")}
+
+	if !strings.HasSuffix(workspaceDir, "/"+itemFullName) || strings.Contains(workspaceDir, "${ITEM_ROOTDIR}") {
+		return roots
+	}
+
+	configured := strings.TrimSuffix(workspaceDir, "/"+itemFullName)
+	configured = filepath.Clean(strings.Replace(configured, "${JENKINS_HOME}", root, -1))
+	if configured != roots[0] {
+		roots = append(roots, configured)
+	}
+
+	return roots
+}
+
+// parseWorkspaceIndex parses the index of the workspace locator, which alternates the full name of a job with the
+// folder of its workspace, and returns the full names by folder.
+func parseWorkspaceIndex(path string) (map[string]string, error) {
+	located := make(map[string]string)
+
+	file, err := os.Open(path)
+	if err != nil {
+		return located, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	for i := 0; i+1 < len(lines); i += 2 {
+		located[lines[i+1]] = lines[i]
+	}
+
+	return located, scanner.Err()
+}
+
+// findOrphans looks for orphaned workspaces in the folder at rel in workspaceRoot. Folders of jobs are followed, the
+// workspaces of jobs that don't exist anymore are returned without looking inside them. Folders that can't be read are
+// logged and skipped.
+func findOrphans(workspaceRoot, rel string, jobs, folders map[string]bool, located map[string]string) []Workspace {
+	dir := filepath.Join(workspaceRoot, filepath.FromSlash(rel))
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Warnf("couldn't look for orphaned workspaces in %s: %v", dir, err)
+		}
+		return nil
+	}
+
+	var orphans []Workspace
+	for _, file := range files {
+		if !file.IsDir() {
+			continue
+		}
+
+		name := file.Name()
+		base := name
+		if i := strings.Index(name, "@"); i > 0 {
+			base = name[:i]
+		}
+
+		fullName := path.Join(rel, base)
+		if job, ok := located[base]; ok && rel == "" {
+			fullName = job
+		}
+
+		switch {
+		case jobs[fullName]:
+			continue
+		case folders[fullName] && base == name:
+			orphans = append(orphans, findOrphans(workspaceRoot, path.Join(rel, name), jobs, folders, located)...)
+		default:
+			orphans = append(orphans, Workspace{
+				Path: filepath.Join(workspaceRoot, filepath.FromSlash(path.Join(rel, name))),
+				Job:  fullName,
+			})
+		}
+	}
+
+	return orphans
+}
+
+// FindStaleJobDirs returns the folders in the jobs folders of the Jenkins folder at root that don't hold a config.xml,
+// sorted by size with the largest first. It looks at no more than filesPerSecond files per second while measuring
+// their size, or at as many as it can when filesPerSecond is 0.
+func FindStaleJobDirs(root string, filesPerSecond int) ([]StaleJobDir, error) {
+	return findStaleJobDirs(root, newRateLimiter(filesPerSecond))
+}
+
+func findStaleJobDirs(root string, limiter *rateLimiter) ([]StaleJobDir, error) {
+	if _, err := os.Stat(root); err != nil {
+		return nil, err
+	}
+
+	stale := findStaleIn(root, "")
+	for i := range stale {
+		stale[i].Bytes = limitedDiskUsage(stale[i].Path, limiter)
+	}
+
+	sort.SliceStable(stale, func(i, j int) bool {
+		return stale[i].Bytes > stale[j].Bytes
+	})
+
+	return stale, nil
+}
+
+// findStaleIn looks for stale job folders in the jobs folder of the item at path, whose full name is fullName. The
+// folders of items with a config.xml are followed, since they can be Jenkins folders.
+func findStaleIn(path, fullName string) []StaleJobDir {
+	jobsPath := filepath.Join(path, "jobs")
+	dirs, err := ioutil.ReadDir(jobsPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Warnf("couldn't look for stale job folders in %s: %v", jobsPath, err)
+		}
+		return nil
+	}
+
+	var stale []StaleJobDir
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+
+		dirPath := filepath.Join(jobsPath, dir.Name())
+		name := strings.TrimPrefix(fullName+"/"+dir.Name(), "/")
+		if _, err := os.Stat(filepath.Join(dirPath, "config.xml")); os.IsNotExist(err) {
+			stale = append(stale, StaleJobDir{Path: dirPath, Job: name})
+			continue
+		}
+		stale = append(stale, findStaleIn(dirPath, name)...)
+	}
+
+	return stale
+}
+
+// limitedDiskUsage returns the total size of the files below path, looking at no more files per second than the
+// limiter allows.
+func limitedDiskUsage(path string, limiter *rateLimiter) int64 {
+	var size int64
+	walkFiles(path, limiter, func(rel string, info os.FileInfo) {
+		size += info.Size()
+	})
+	return size
+}
@@ -0,0 +1,155 @@
+// Copyright 2019 Lander Van den Bulcke
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindOrphanedWorkspaces(t *testing.T) {
+	orphans, err := FindOrphanedWorkspaces("testdata", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	workspace := filepath.Join("testdata", "workspace")
+	expected := []Workspace{
+		{Path: filepath.Join(workspace, "oldjob"), Job: "oldjob", Bytes: fileSizes(t, "testdata/workspace/oldjob/src/main.go")},
+		{Path: filepath.Join(workspace, "folder", "removedjob"), Job: "folder/removedjob", Bytes: fileSizes(t, "testdata/workspace/folder/removedjob/build.sh")},
+		{Path: filepath.Join(workspace, "folder", "removedjob@tmp"), Job: "folder/removedjob", Bytes: fileSizes(t, "testdata/workspace/folder/removedjob@tmp/durable.log")},
+		{Path: filepath.Join(workspace, "multibranch_PR-41"), Job: "multibranch/PR-41", Bytes: fileSizes(t, "testdata/workspace/multibranch_PR-41/Jenkinsfile")},
+	}
+
+	if !reflect.DeepEqual(orphans, expected) {
+		t.Errorf("orphaned workspaces are %+v, expected %+v", orphans, expected)
+	}
+}
+
+func TestFindOrphanedWorkspacesWithoutBuilds(t *testing.T) {
+	// livejob was never built in its own folder, its builds are in the custom builds folder
+	orphans, err := FindOrphanedWorkspaces(filepath.Join("testdata", "stalehome"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	workspace := filepath.Join("testdata", "stalehome", "workspace")
+	expected := []Workspace{
+		{Path: filepath.Join(workspace, "folder", "renamedjob"), Job: "folder/renamedjob", Bytes: fileSizes(t, "testdata/stalehome/workspace/folder/renamedjob/build.sh")},
+	}
+
+	if !reflect.DeepEqual(orphans, expected) {
+		t.Errorf("orphaned workspaces are %+v, expected %+v", orphans, expected)
+	}
+}
+
+func TestJobFullNames(t *testing.T) {
+	// the builds folder in the Jenkins folder doesn't make it a job
+	jobs, folders, err := jobFullNames(filepath.Join("testdata", "stalehome"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := map[string]bool{"livejob": true}; !reflect.DeepEqual(jobs, expected) {
+		t.Errorf("jobs are %v, expected %v", jobs, expected)
+	}
+	if expected := map[string]bool{"folder": true}; !reflect.DeepEqual(folders, expected) {
+		t.Errorf("folders are %v, expected %v", folders, expected)
+	}
+
+	jobs, _, err = jobFullNames("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"folder/jobwithoutbuilds", "multibranch/feature%2Flogin", "mavenjob/com.example$app"} {
+		if !jobs[name] {
+			t.Errorf("job %s isn't found", name)
+		}
+	}
+}
+
+func TestAddJobName(t *testing.T) {
+	jobs := make(map[string]bool)
+	folders := make(map[string]bool)
+
+	for _, name := range []string{"", "/", "/rootjob", "folder/sub/job"} {
+		addJobName(name, jobs, folders)
+	}
+
+	if expected := map[string]bool{"rootjob": true, "folder/sub/job": true}; !reflect.DeepEqual(jobs, expected) {
+		t.Errorf("jobs are %v, expected %v", jobs, expected)
+	}
+	if expected := map[string]bool{"folder": true, "folder/sub": true}; !reflect.DeepEqual(folders, expected) {
+		t.Errorf("folders are %v, expected %v", folders, expected)
+	}
+}
+
+func TestFindStaleJobDirs(t *testing.T) {
+	stale, err := FindStaleJobDirs(filepath.Join("testdata", "stalehome"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jobs := filepath.Join("testdata", "stalehome", "jobs")
+	expected := []StaleJobDir{
+		{Path: filepath.Join(jobs, "folder", "jobs", "renamedjob"), Job: "folder/renamedjob", Bytes: fileSizes(t, "testdata/stalehome/jobs/folder/jobs/renamedjob/builds/1/log")},
+		{Path: filepath.Join(jobs, "oldjob"), Job: "oldjob", Bytes: fileSizes(t, "testdata/stalehome/jobs/oldjob/nextBuildNumber")},
+	}
+
+	if !reflect.DeepEqual(stale, expected) {
+		t.Errorf("stale job folders are %+v, expected %+v", stale, expected)
+	}
+
+	stale, err = FindStaleJobDirs("testdata", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stale) != 0 {
+		t.Errorf("found stale job folders %+v, expected none", stale)
+	}
+}
+
+func TestWorkspaceRoots(t *testing.T) {
+	tests := []struct {
+		workspaceDir string
+		expected     []string
+	}{
+		{DefaultWorkspaceDir, []string{"/var/lib/jenkins/workspace"}},
+		{"${JENKINS_HOME}/workspaces/${ITEM_FULL_NAME}", []string{"/var/lib/jenkins/workspace", "/var/lib/jenkins/workspaces"}},
+		{"/data/jenkins/${ITEM_FULL_NAME}", []string{"/var/lib/jenkins/workspace", "/data/jenkins"}},
+		{"${ITEM_ROOTDIR}/workspace", []string{"/var/lib/jenkins/workspace"}},
+	}
+
+	for _, test := range tests {
+		if roots := workspaceRoots("/var/lib/jenkins", test.workspaceDir); !reflect.DeepEqual(roots, test.expected) {
+			t.Errorf("workspace roots of %s are %v, expected %v", test.workspaceDir, roots, test.expected)
+		}
+	}
+}
+
+func TestParseWorkspaceIndex(t *testing.T) {
+	located, err := parseWorkspaceIndex("testdata/workspace/workspaces.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"multibranch_main":  "multibranch/main",
+		"multibranch_PR-41": "multibranch/PR-41",
+	}
+	if !reflect.DeepEqual(located, expected) {
+		t.Errorf("workspace index is %v, expected %v", located, expected)
+	}
+}
@@ -14,13 +14,17 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"net/http"
+	"os"
 	"regexp"
 	"strconv"
 	"strings"
 	"sync"
+	"text/tabwriter"
 	"time"
 
 	"github.com/landervdb/jenkins_exporter/jenkins"
@@ -33,9 +37,18 @@ import (
 var (
 	bind        = flag.String("metrics.bind", ":9506", "Address to expose the metrics on")
 	path        = flag.String("metrics.path", "/metrics", "Path to expose the metrics on")
+	depsPath    = flag.String("dependencies.path", "/dependencies", "Path to expose the job dependency graph on")
 	ignoreList  = flag.String("jenkins.ignore", "", "Comma-separated list of folders to ignore")
 	jenkinsPath = flag.String("jenkins.path", "/var/lib/jenkins", "Path to the Jenkins folder")
+	modules     = flag.Bool("jenkins.maven-modules", false, "Export the builds of the modules of Maven projects")
+	queueAge    = flag.Duration("jenkins.queue-max-age", time.Hour, "Age after which the queue snapshot in queue.xml is considered stale")
+	windows     = flag.String("jenkins.utilisation-windows", "1h,24h,168h", "Comma-separated list of windows to compute the executor utilisation of the nodes over")
+	diskScan    = flag.Duration("jenkins.disk-scan-interval", 0, "Interval between scans of the disk usage of the builds of all jobs, 0 disables the scans")
+	diskRate    = flag.Int("jenkins.disk-scan-rate", 1000, "Maximum number of files the disk usage scan looks at per second, 0 for no limit")
+	diskTopJobs = flag.Int("jenkins.disk-top-jobs", 10, "Number of jobs per folder to export the disk usage of, the others are added up, 0 for all jobs")
+	grace       = flag.Duration("jenkins.schedule-grace", 15*time.Minute, "Time a scheduled build may take to start before it is reported as missed")
 	envVars     = flag.String("jenkins.envvars", "", "Custom environment variables to parse into metrics. Format: ENVVAR1:metric_name;ENVVAR2:metric_name,...")
+	orphans     = flag.Bool("report.orphaned-workspaces", false, "Print the workspaces of jobs that don't exist anymore and the stale job folders, and exit")
 	logLevel    = flag.String("log.level", "INFO", "The minimal log level to be displayed")
 )
 
@@ -51,10 +64,70 @@ type Collector struct {
 	up                 *prometheus.Desc
 	collectDuration    *prometheus.Desc
 	collectFailures    prometheus.Counter
+	controllerInfo     *prometheus.GaugeVec
+	controllerExecutor prometheus.Gauge
+	pluginInfo         *prometheus.GaugeVec
+	nodeInfo           *prometheus.GaugeVec
+	nodeExecutors      *prometheus.GaugeVec
+	nodeLabels         *prometheus.GaugeVec
+	nodeOffline        *prometheus.GaugeVec
+	nodeBuilds         *prometheus.GaugeVec
+	nodeBuildTime      *prometheus.GaugeVec
+	nodeBusyTime       *prometheus.GaugeVec
+	nodeUtilisation    *prometheus.GaugeVec
+	nodePeakBuilds     *prometheus.GaugeVec
+	utilisationWindows []utilisationWindow
+	queueItems         *prometheus.GaugeVec
+	queueOldestAge     prometheus.Gauge
+	queueSnapshotTime  prometheus.Gauge
+	queueStale         prometheus.Gauge
+	queueMaxAge        time.Duration
+	diskScanner        *jenkins.DiskScanner
+	diskTopJobs        int
+	jobDiskBytes       *prometheus.GaugeVec
+	diskScanTimestamp  prometheus.Gauge
+	diskScanDuration   prometheus.Gauge
+	orphanedWorkspaces prometheus.Gauge
+	orphanedBytes      prometheus.Gauge
+	staleJobDirs       prometheus.Gauge
+	staleJobDirBytes   prometheus.Gauge
 	lastBuildNumber    *prometheus.GaugeVec
 	lastBuildTimestamp *prometheus.GaugeVec
 	lastBuildDuration  *prometheus.GaugeVec
+	pipelineFailure    *prometheus.GaugeVec
+	pipelineFailures   *prometheus.GaugeVec
+	coverageRatio      *prometheus.GaugeVec
+	analysisIssues     *prometheus.GaugeVec
+	indexingResult     *prometheus.GaugeVec
+	indexingTimestamp  *prometheus.GaugeVec
+	indexingDuration   *prometheus.GaugeVec
+	jobInfo            *prometheus.GaugeVec
+	jobDisabled        *prometheus.GaugeVec
+	scmInfo            *prometheus.GaugeVec
+	parameterInfo      *prometheus.GaugeVec
+	jobConcurrentBuild *prometheus.GaugeVec
+	folderJobs         *prometheus.GaugeVec
+	folderJobCount     *prometheus.GaugeVec
+	folderFailedJobs   *prometheus.GaugeVec
+	folderDisabledJobs *prometheus.GaugeVec
+	folderWorstHealth  *prometheus.GaugeVec
+	folderBuildTime    *prometheus.GaugeVec
+	healthScore        *prometheus.GaugeVec
+	retainedBuilds     *prometheus.GaugeVec
+	discarder          *prometheus.GaugeVec
+	buildsBytes        *prometheus.GaugeVec
+	nextBuildNumber    *prometheus.GaugeVec
+	buildNumberGap     *prometheus.GaugeVec
+	nextScheduled      *prometheus.GaugeVec
+	missedSchedule     *prometheus.GaugeVec
+	demandForecast     *prometheus.GaugeVec
+	scheduleGrace      time.Duration
+	jobDependency      *prometheus.GaugeVec
+	chainLeadTime      *prometheus.GaugeVec
+	chainHopWait       *prometheus.GaugeVec
 	customGauges       map[string]*prometheus.GaugeVec
+	graphMutex         sync.Mutex
+	dependencies       *jenkins.DependencyGraph
 }
 
 // NewCollector creates an instance of Collector.
@@ -78,13 +151,187 @@ func NewCollector(opts jenkins.JobPathOpts) *Collector {
 				Help:      "The number of collection failures since the exporter was started",
 			},
 		),
+		controllerInfo: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "controller_info",
+				Help:      "Information about the Jenkins controller, always 1",
+			},
+			[]string{"version", "security_realm", "authorization_strategy"},
+		),
+		controllerExecutor: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "controller_executors",
+				Help:      "Number of executors on the built-in node of the Jenkins controller",
+			},
+		),
+		pluginInfo: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "plugin_info",
+				Help:      "Information about the plugins installed in the plugins folder, always 1",
+			},
+			[]string{"plugin", "version", "required_core_version", "disabled", "pinned", "version_mismatch"},
+		),
+		nodeInfo: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "node_info",
+				Help:      "Information about the agents configured in the nodes folder, always 1",
+			},
+			[]string{"node", "launcher"},
+		),
+		nodeExecutors: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "node_executors",
+				Help:      "Number of executors of the agent",
+			},
+			[]string{"node"},
+		),
+		nodeLabels: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "node_labels",
+				Help:      "Labels assigned to the agent, always 1",
+			},
+			[]string{"node", "label"},
+		),
+		nodeOffline: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "node_temporarily_offline",
+				Help:      "Whether the agent was marked temporarily offline",
+			},
+			[]string{"node"},
+		),
+		nodeBuilds: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "node_builds",
+				Help:      "Number of retained builds that ran on the node by result",
+			},
+			[]string{"node", "result"},
+		),
+		nodeBuildTime: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "node_build_duration_seconds",
+				Help:      "Total duration of the retained builds that ran on the node",
+			},
+			[]string{"node"},
+		),
+		nodeBusyTime: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "node_busy_executor_seconds",
+				Help:      "Total time the executors of the node were running retained or running builds during the window, excluding pipelines",
+			},
+			[]string{"node", "window"},
+		),
+		nodeUtilisation: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "node_executor_utilisation_ratio",
+				Help:      "Fraction of the executor capacity of the node used by retained or running builds during the window, excluding pipelines",
+			},
+			[]string{"node", "window"},
+		),
+		nodePeakBuilds: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "node_peak_concurrent_builds",
+				Help:      "Largest number of retained or running builds on the node at the same time during the window, excluding pipelines",
+			},
+			[]string{"node", "window"},
+		),
+		queueItems: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "queue_items",
+				Help:      "Number of items in the queue snapshot by job and state",
+			},
+			jobLabelNames("state"),
+		),
+		queueOldestAge: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "queue_oldest_item_age_seconds",
+				Help:      "Time the oldest item in the queue snapshot has been waiting",
+			},
+		),
+		queueSnapshotTime: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "queue_snapshot_timestamp_seconds",
+				Help:      "Time Jenkins last saved the queue snapshot",
+			},
+		),
+		queueStale: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "queue_snapshot_stale",
+				Help:      "Whether the queue snapshot is too old to describe the current queue",
+			},
+		),
+		jobDiskBytes: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "job_disk_bytes",
+				Help:      "Size of the builds of the job on disk by kind of file, as of the last disk usage scan",
+			},
+			[]string{"folder", "jenkins_job", "kind"},
+		),
+		diskScanTimestamp: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "disk_scan_timestamp_seconds",
+				Help:      "Time the last completed disk usage scan started",
+			},
+		),
+		diskScanDuration: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "disk_scan_duration_seconds",
+				Help:      "Time the last completed disk usage scan took",
+			},
+		),
+		orphanedWorkspaces: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "orphaned_workspaces",
+				Help:      "Number of workspaces on the built-in node of jobs that don't exist anymore, as of the last disk usage scan",
+			},
+		),
+		orphanedBytes: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "orphaned_workspace_bytes",
+				Help:      "Size of the workspaces on the built-in node of jobs that don't exist anymore, as of the last disk usage scan",
+			},
+		),
+		staleJobDirs: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "stale_job_dirs",
+				Help:      "Number of folders in the jobs folders without a config.xml, as of the last disk usage scan",
+			},
+		),
+		staleJobDirBytes: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "stale_job_dir_bytes",
+				Help:      "Size of the folders in the jobs folders without a config.xml, as of the last disk usage scan",
+			},
+		),
 		lastBuildNumber: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace: namespace,
 				Name:      "last_build_number",
 				Help:      "Build number of the last build",
 			},
-			[]string{"folder", "jenkins_job", "result"},
+			jobLabelNames("result"),
 		),
 		lastBuildTimestamp: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
@@ -92,7 +339,7 @@ func NewCollector(opts jenkins.JobPathOpts) *Collector {
 				Name:      "last_build_timestamp_seconds",
 				Help:      "Timestamp of the last build",
 			},
-			[]string{"folder", "jenkins_job", "result"},
+			jobLabelNames("result"),
 		),
 		lastBuildDuration: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
@@ -100,8 +347,249 @@ func NewCollector(opts jenkins.JobPathOpts) *Collector {
 				Name:      "last_build_duration_seconds",
 				Help:      "Duration of the last build",
 			},
+			jobLabelNames("result"),
+		),
+		pipelineFailure: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "pipeline_failure",
+				Help:      "Stage and step that caused the last failed pipeline build",
+			},
+			jobLabelNames("stage", "step"),
+		),
+		pipelineFailures: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "pipeline_failures",
+				Help:      "Number of retained failed pipeline builds per stage and step that caused the failure",
+			},
+			jobLabelNames("stage", "step"),
+		),
+		coverageRatio: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "job_coverage_ratio",
+				Help:      "Code coverage ratio of the last successful build",
+			},
+			jobLabelNames("type"),
+		),
+		analysisIssues: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "job_static_analysis_issues",
+				Help:      "Number of static analysis issues reported in the last completed build",
+			},
+			jobLabelNames("tool", "severity"),
+		),
+		indexingResult: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "indexing_last_result",
+				Help:      "Result of the last branch indexing of a multibranch project or organization folder",
+			},
 			[]string{"folder", "jenkins_job", "result"},
 		),
+		indexingTimestamp: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "indexing_last_timestamp_seconds",
+				Help:      "Timestamp of the last branch indexing of a multibranch project or organization folder",
+			},
+			[]string{"folder", "jenkins_job"},
+		),
+		indexingDuration: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "indexing_last_duration_seconds",
+				Help:      "Duration of the last branch indexing of a multibranch project or organization folder",
+			},
+			[]string{"folder", "jenkins_job"},
+		),
+		jobInfo: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "job_info",
+				Help:      "Information about the job from its configuration",
+			},
+			jobLabelNames("type", "assigned_node"),
+		),
+		scmInfo: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "job_scm_info",
+				Help:      "Repositories and branch specs the job checks out",
+			},
+			jobLabelNames("scm_type", "repository", "branch_spec"),
+		),
+		parameterInfo: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "job_parameter_info",
+				Help:      "Build parameters defined by the job",
+			},
+			jobLabelNames("parameter", "type", "has_default"),
+		),
+		jobDisabled: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "job_disabled",
+				Help:      "Whether the job is disabled",
+			},
+			jobLabelNames(),
+		),
+		jobConcurrentBuild: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "job_concurrent_build",
+				Help:      "Whether the job allows concurrent builds",
+			},
+			jobLabelNames(),
+		),
+		folderJobs: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "folder_jobs",
+				Help:      "Number of jobs and folders directly in the folder by type, including jobs that were never built",
+			},
+			[]string{"folder", "type"},
+		),
+		folderJobCount: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "folder_job_count",
+				Help:      "Number of jobs in the folder",
+			},
+			[]string{"folder", "recursive"},
+		),
+		folderFailedJobs: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "folder_failed_jobs",
+				Help:      "Number of jobs in the folder whose last build failed",
+			},
+			[]string{"folder", "recursive"},
+		),
+		folderDisabledJobs: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "folder_disabled_jobs",
+				Help:      "Number of disabled jobs in the folder",
+			},
+			[]string{"folder", "recursive"},
+		),
+		folderWorstHealth: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "folder_worst_health_score",
+				Help:      "Lowest health score of the jobs in the folder",
+			},
+			[]string{"folder", "recursive"},
+		),
+		folderBuildTime: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "folder_build_duration_seconds",
+				Help:      "Total duration of the retained builds of the jobs in the folder",
+			},
+			[]string{"folder", "recursive"},
+		),
+		healthScore: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "job_health_score",
+				Help:      "Health score of the job from its build stability and test results, as shown by the Jenkins weather icon",
+			},
+			jobLabelNames(),
+		),
+		retainedBuilds: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "job_retained_builds",
+				Help:      "Number of builds of the job that are retained on disk",
+			},
+			jobLabelNames(),
+		),
+		discarder: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "job_build_discarder_configured",
+				Help:      "Whether the job has a build discarder that removes old builds",
+			},
+			jobLabelNames(),
+		),
+		buildsBytes: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "job_builds_bytes",
+				Help:      "Total size of the retained builds of the job on disk as of the last disk usage scan, only exported when -jenkins.disk-scan-interval is set",
+			},
+			jobLabelNames(),
+		),
+		nextBuildNumber: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "job_next_build_number",
+				Help:      "Number the next build of the job will get",
+			},
+			jobLabelNames(),
+		),
+		buildNumberGap: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "job_build_number_gap",
+				Help:      "Number of builds between the newest build on disk and the last build number that was handed out",
+			},
+			jobLabelNames(),
+		),
+		nextScheduled: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "job_next_scheduled_timestamp_seconds",
+				Help:      "Next time the cron spec of a trigger of the job fires",
+			},
+			jobLabelNames("trigger"),
+		),
+		missedSchedule: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "job_missed_schedule",
+				Help:      "Whether the timer trigger of the job fired without a build being started",
+			},
+			jobLabelNames(),
+		),
+		jobDependency: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "job_dependency",
+				Help:      "Builds of the upstream job trigger builds of the downstream job",
+			},
+			[]string{"upstream", "downstream"},
+		),
+		chainLeadTime: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "chain_lead_time_seconds",
+				Help:      "Time from the start of the root build of the upstream chain to the end of the last completed build of the job",
+			},
+			jobLabelNames("root"),
+		),
+		chainHopWait: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "chain_hop_wait_seconds",
+				Help:      "Time between the end of an upstream build and the start of the build it triggered, in the chain of the last completed build of the job",
+			},
+			jobLabelNames("upstream", "downstream"),
+		),
+		dependencies: jenkins.NewDependencyGraph(),
+		demandForecast: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "executor_demand_forecast",
+				Help:      "Expected number of concurrent builds started by timer triggers during each hour (UTC) of the next day, by assigned node label",
+			},
+			[]string{"label", "hour"},
+		),
 	}
 }
 
@@ -110,9 +598,62 @@ func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.up
 	ch <- c.collectDuration
 	c.collectFailures.Describe(ch)
+	c.controllerInfo.Describe(ch)
+	c.controllerExecutor.Describe(ch)
+	c.pluginInfo.Describe(ch)
+	c.nodeInfo.Describe(ch)
+	c.nodeExecutors.Describe(ch)
+	c.nodeLabels.Describe(ch)
+	c.nodeOffline.Describe(ch)
+	c.nodeBuilds.Describe(ch)
+	c.nodeBuildTime.Describe(ch)
+	c.nodeBusyTime.Describe(ch)
+	c.nodeUtilisation.Describe(ch)
+	c.nodePeakBuilds.Describe(ch)
+	c.queueItems.Describe(ch)
+	c.queueOldestAge.Describe(ch)
+	c.queueSnapshotTime.Describe(ch)
+	c.queueStale.Describe(ch)
+	c.jobDiskBytes.Describe(ch)
+	c.diskScanTimestamp.Describe(ch)
+	c.diskScanDuration.Describe(ch)
+	c.orphanedWorkspaces.Describe(ch)
+	c.orphanedBytes.Describe(ch)
+	c.staleJobDirs.Describe(ch)
+	c.staleJobDirBytes.Describe(ch)
 	c.lastBuildNumber.Describe(ch)
 	c.lastBuildTimestamp.Describe(ch)
 	c.lastBuildDuration.Describe(ch)
+	c.pipelineFailure.Describe(ch)
+	c.pipelineFailures.Describe(ch)
+	c.coverageRatio.Describe(ch)
+	c.analysisIssues.Describe(ch)
+	c.indexingResult.Describe(ch)
+	c.indexingTimestamp.Describe(ch)
+	c.indexingDuration.Describe(ch)
+	c.jobInfo.Describe(ch)
+	c.jobDisabled.Describe(ch)
+	c.scmInfo.Describe(ch)
+	c.parameterInfo.Describe(ch)
+	c.jobConcurrentBuild.Describe(ch)
+	c.folderJobs.Describe(ch)
+	c.folderJobCount.Describe(ch)
+	c.folderFailedJobs.Describe(ch)
+	c.folderDisabledJobs.Describe(ch)
+	c.folderWorstHealth.Describe(ch)
+	c.folderBuildTime.Describe(ch)
+	c.healthScore.Describe(ch)
+	c.retainedBuilds.Describe(ch)
+	c.discarder.Describe(ch)
+	c.buildsBytes.Describe(ch)
+	c.nextBuildNumber.Describe(ch)
+	c.buildNumberGap.Describe(ch)
+	c.nextScheduled.Describe(ch)
+	c.missedSchedule.Describe(ch)
+	c.demandForecast.Describe(ch)
+	c.jobDependency.Describe(ch)
+	c.chainLeadTime.Describe(ch)
+	c.chainHopWait.Describe(ch)
 
 	for _, cg := range c.customGauges {
 		cg.Describe(ch)
@@ -133,9 +674,56 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) {
 		ch <- prometheus.MustNewConstMetric(c.collectDuration, prometheus.GaugeValue, duration)
 	}()
 
+	// Version, stage, step, tool, result, type, trigger, SCM and parameter labels change over time, so start from scratch every collection
+	c.controllerInfo.Reset()
+	c.pluginInfo.Reset()
+	c.nodeInfo.Reset()
+	c.nodeExecutors.Reset()
+	c.nodeLabels.Reset()
+	c.nodeOffline.Reset()
+	c.nodeBuilds.Reset()
+	c.nodeBuildTime.Reset()
+	c.nodeBusyTime.Reset()
+	c.nodeUtilisation.Reset()
+	c.nodePeakBuilds.Reset()
+	c.queueItems.Reset()
+	c.healthScore.Reset()
+	c.retainedBuilds.Reset()
+	c.discarder.Reset()
+	c.nextBuildNumber.Reset()
+	c.buildNumberGap.Reset()
+	c.buildsBytes.Reset()
+	c.jobDiskBytes.Reset()
+	c.pipelineFailure.Reset()
+	c.pipelineFailures.Reset()
+	c.coverageRatio.Reset()
+	c.analysisIssues.Reset()
+	c.indexingResult.Reset()
+	c.indexingTimestamp.Reset()
+	c.indexingDuration.Reset()
+	c.jobInfo.Reset()
+	c.jobDisabled.Reset()
+	c.jobConcurrentBuild.Reset()
+	c.scmInfo.Reset()
+	c.parameterInfo.Reset()
+	c.folderJobs.Reset()
+	c.folderJobCount.Reset()
+	c.folderFailedJobs.Reset()
+	c.folderDisabledJobs.Reset()
+	c.folderWorstHealth.Reset()
+	c.folderBuildTime.Reset()
+	c.nextScheduled.Reset()
+	c.missedSchedule.Reset()
+	c.demandForecast.Reset()
+	c.jobDependency.Reset()
+	c.chainLeadTime.Reset()
+	c.chainHopWait.Reset()
+
 	jobPaths := make(chan jenkins.JobPath)
+	trees := make(chan jenkins.JobTree, 1)
 	go func() {
-		err := jenkins.GetJobPaths(c.opts, jobPaths)
+		tree, err := jenkins.WalkJobs(c.opts, jobPaths)
+		trees <- tree
 		if err != nil {
 			log.Errorf("collecting job paths failed: %v", err)
 			ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, 0)
@@ -159,59 +747,469 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) {
 		close(jobs)
 	}()
 
+	var scheduledJobs []jenkins.Job
+	folders := jenkins.NewFolderAggregator()
+	nodes := jenkins.NewNodeAggregator()
+	dependencies := jenkins.NewDependencyGraph()
+	var triggeredJobs []jenkins.Job
+	exported := make(map[string]bool)
 	for job := range jobs {
+		if job.Axes == "" && job.Module == "" && job.Branch == "" {
+			exported[job.FullName()] = true
+		}
+		c.jobInfo.WithLabelValues(jobLabelValues(job, job.Config.Type, job.Config.AssignedNode)...).Set(1)
+		c.jobDisabled.WithLabelValues(jobLabelValues(job)...).Set(boolToFloat(job.Config.Disabled))
+		for _, parameter := range job.Config.Parameters {
+			c.parameterInfo.WithLabelValues(jobLabelValues(job, parameter.Name, parameter.Type, strconv.FormatBool(parameter.HasDefault))...).Set(1)
+		}
+		if scm := job.Config.SCM; scm.Type != "" {
+			c.scmInfo.WithLabelValues(jobLabelValues(job, scm.Type, strings.Join(scm.Repositories, ","), strings.Join(scm.BranchSpecs, ","))...).Set(1)
+		}
+		c.jobConcurrentBuild.WithLabelValues(jobLabelValues(job)...).Set(boolToFloat(job.Config.ConcurrentBuild))
+		if score, ok := job.HealthScore(); ok {
+			c.healthScore.WithLabelValues(jobLabelValues(job)...).Set(float64(score))
+		}
+		c.retainedBuilds.WithLabelValues(jobLabelValues(job)...).Set(float64(job.RetainedBuilds))
+		c.discarder.WithLabelValues(jobLabelValues(job)...).Set(boolToFloat(job.Config.BuildDiscarder.DiscardsBuilds()))
+		if job.NextBuildNumber != 0 {
+			c.nextBuildNumber.WithLabelValues(jobLabelValues(job)...).Set(float64(job.NextBuildNumber))
+			c.buildNumberGap.WithLabelValues(jobLabelValues(job)...).Set(float64(job.BuildNumberGap()))
+		}
+		folders.Add(job)
+		nodes.Add(job)
+		dependencies.Add(job)
+		// matrix configurations and Maven modules are triggered by their parent, which reports the chain itself
+		if len(job.LastBuild.UpstreamCauses) > 0 && job.Axes == "" && job.Module == "" {
+			triggeredJobs = append(triggeredJobs, job)
+		}
+		c.collectSchedules(job, startTime)
+		if len(job.Config.Triggers) > 0 {
+			scheduledJobs = append(scheduledJobs, job)
+		}
+
 		if job.LastSuccessfulBuild.Number != 0 {
-			c.lastBuildNumber.WithLabelValues(job.Folder, job.Name, "successful").Set(float64(job.LastSuccessfulBuild.Number))
-			c.lastBuildTimestamp.WithLabelValues(job.Folder, job.Name, "successful").Set(float64(job.LastSuccessfulBuild.Timestamp) / 1000)
-			c.lastBuildDuration.WithLabelValues(job.Folder, job.Name, "successful").Set(float64(job.LastSuccessfulBuild.Duration) / 1000)
-			populateCustomGauges(job.Folder, job.Name, "successful", job.LastSuccessfulBuild, c.customGauges)
+			c.lastBuildNumber.WithLabelValues(jobLabelValues(job, "successful")...).Set(float64(job.LastSuccessfulBuild.Number))
+			c.lastBuildTimestamp.WithLabelValues(jobLabelValues(job, "successful")...).Set(float64(job.LastSuccessfulBuild.Timestamp) / 1000)
+			c.lastBuildDuration.WithLabelValues(jobLabelValues(job, "successful")...).Set(float64(job.LastSuccessfulBuild.Duration) / 1000)
+			populateCustomGauges(jobLabelValues(job, "successful"), job.LastSuccessfulBuild, c.customGauges)
 		}
 
 		if job.LastUnsuccessfulBuild.Number != 0 {
-			c.lastBuildNumber.WithLabelValues(job.Folder, job.Name, "unsuccessful").Set(float64(job.LastUnsuccessfulBuild.Number))
-			c.lastBuildTimestamp.WithLabelValues(job.Folder, job.Name, "unsuccessful").Set(float64(job.LastUnsuccessfulBuild.Timestamp) / 1000)
-			c.lastBuildDuration.WithLabelValues(job.Folder, job.Name, "unsuccessful").Set(float64(job.LastUnsuccessfulBuild.Duration) / 1000)
-			populateCustomGauges(job.Folder, job.Name, "unsuccessful", job.LastUnsuccessfulBuild, c.customGauges)
+			c.lastBuildNumber.WithLabelValues(jobLabelValues(job, "unsuccessful")...).Set(float64(job.LastUnsuccessfulBuild.Number))
+			c.lastBuildTimestamp.WithLabelValues(jobLabelValues(job, "unsuccessful")...).Set(float64(job.LastUnsuccessfulBuild.Timestamp) / 1000)
+			c.lastBuildDuration.WithLabelValues(jobLabelValues(job, "unsuccessful")...).Set(float64(job.LastUnsuccessfulBuild.Duration) / 1000)
+			populateCustomGauges(jobLabelValues(job, "unsuccessful"), job.LastUnsuccessfulBuild, c.customGauges)
 		}
 
 		if job.LastStableBuild.Number != 0 {
-			c.lastBuildNumber.WithLabelValues(job.Folder, job.Name, "stable").Set(float64(job.LastStableBuild.Number))
-			c.lastBuildTimestamp.WithLabelValues(job.Folder, job.Name, "stable").Set(float64(job.LastStableBuild.Timestamp) / 1000)
-			c.lastBuildDuration.WithLabelValues(job.Folder, job.Name, "stable").Set(float64(job.LastStableBuild.Duration) / 1000)
-			populateCustomGauges(job.Folder, job.Name, "stable", job.LastStableBuild, c.customGauges)
+			c.lastBuildNumber.WithLabelValues(jobLabelValues(job, "stable")...).Set(float64(job.LastStableBuild.Number))
+			c.lastBuildTimestamp.WithLabelValues(jobLabelValues(job, "stable")...).Set(float64(job.LastStableBuild.Timestamp) / 1000)
+			c.lastBuildDuration.WithLabelValues(jobLabelValues(job, "stable")...).Set(float64(job.LastStableBuild.Duration) / 1000)
+			populateCustomGauges(jobLabelValues(job, "stable"), job.LastStableBuild, c.customGauges)
 		}
 
 		if job.LastUnstableBuild.Number != 0 {
-			c.lastBuildNumber.WithLabelValues(job.Folder, job.Name, "unstable").Set(float64(job.LastUnstableBuild.Number))
-			c.lastBuildTimestamp.WithLabelValues(job.Folder, job.Name, "unstable").Set(float64(job.LastUnstableBuild.Timestamp) / 1000)
-			c.lastBuildDuration.WithLabelValues(job.Folder, job.Name, "unstable").Set(float64(job.LastUnstableBuild.Duration) / 1000)
-			populateCustomGauges(job.Folder, job.Name, "unstable", job.LastUnstableBuild, c.customGauges)
+			c.lastBuildNumber.WithLabelValues(jobLabelValues(job, "unstable")...).Set(float64(job.LastUnstableBuild.Number))
+			c.lastBuildTimestamp.WithLabelValues(jobLabelValues(job, "unstable")...).Set(float64(job.LastUnstableBuild.Timestamp) / 1000)
+			c.lastBuildDuration.WithLabelValues(jobLabelValues(job, "unstable")...).Set(float64(job.LastUnstableBuild.Duration) / 1000)
+			populateCustomGauges(jobLabelValues(job, "unstable"), job.LastUnstableBuild, c.customGauges)
 		}
 
 		if job.LastFailedBuild.Number != 0 {
-			c.lastBuildNumber.WithLabelValues(job.Folder, job.Name, "failed").Set(float64(job.LastFailedBuild.Number))
-			c.lastBuildTimestamp.WithLabelValues(job.Folder, job.Name, "failed").Set(float64(job.LastFailedBuild.Timestamp) / 1000)
-			c.lastBuildDuration.WithLabelValues(job.Folder, job.Name, "failed").Set(float64(job.LastFailedBuild.Duration) / 1000)
-			populateCustomGauges(job.Folder, job.Name, "failed", job.LastFailedBuild, c.customGauges)
+			c.lastBuildNumber.WithLabelValues(jobLabelValues(job, "failed")...).Set(float64(job.LastFailedBuild.Number))
+			c.lastBuildTimestamp.WithLabelValues(jobLabelValues(job, "failed")...).Set(float64(job.LastFailedBuild.Timestamp) / 1000)
+			c.lastBuildDuration.WithLabelValues(jobLabelValues(job, "failed")...).Set(float64(job.LastFailedBuild.Duration) / 1000)
+			populateCustomGauges(jobLabelValues(job, "failed"), job.LastFailedBuild, c.customGauges)
+		}
+
+		for t, ratio := range job.LastSuccessfulBuild.Coverage {
+			c.coverageRatio.WithLabelValues(jobLabelValues(job, t)...).Set(ratio)
+		}
+
+		for tool, severities := range job.LastBuild.Issues {
+			for severity, count := range severities {
+				c.analysisIssues.WithLabelValues(jobLabelValues(job, tool, severity)...).Set(float64(count))
+			}
+		}
+
+		for _, failure := range job.LastFailedBuild.PipelineFailures {
+			c.pipelineFailure.WithLabelValues(jobLabelValues(job, failure.Stage, failure.Step)...).Set(1)
+		}
+
+		for _, build := range job.Builds {
+			for _, failure := range build.PipelineFailures {
+				c.pipelineFailures.WithLabelValues(jobLabelValues(job, failure.Stage, failure.Step)...).Inc()
+			}
 		}
 
 		log.Debugf("Parsed job %s in folder %s", job.Name, job.Folder)
 	}
+	// the builds that weren't seen during this collection were discarded or belong to jobs that are gone
+	jenkins.PruneBuildCache(startTime)
+
+	for _, summary := range folders.Summaries() {
+		recursive := strconv.FormatBool(summary.Recursive)
+		c.folderJobCount.WithLabelValues(summary.Folder, recursive).Set(float64(summary.Jobs))
+		c.folderFailedJobs.WithLabelValues(summary.Folder, recursive).Set(float64(summary.FailedJobs))
+		c.folderDisabledJobs.WithLabelValues(summary.Folder, recursive).Set(float64(summary.DisabledJobs))
+		c.folderBuildTime.WithLabelValues(summary.Folder, recursive).Set(summary.BuildDuration.Seconds())
+		if summary.WorstHealth != -1 {
+			c.folderWorstHealth.WithLabelValues(summary.Folder, recursive).Set(float64(summary.WorstHealth))
+		}
+	}
+
+	// the items and indexings were found in the same walk as the jobs
+	tree := <-trees
+	for _, item := range tree.Items {
+		c.folderJobs.WithLabelValues(item.Folder, item.Config.Type).Inc()
+		// multibranch projects, organization folders and jobs that were never built have no job path
+		if scm := item.Config.SCM; scm.Type != "" && !exported[item.FullName()] {
+			labels := jobLabelValues(jenkins.Job{Folder: item.Folder, Name: item.Name}, scm.Type, strings.Join(scm.Repositories, ","), strings.Join(scm.BranchSpecs, ","))
+			c.scmInfo.WithLabelValues(labels...).Set(1)
+		}
+	}
+
+	for _, dependency := range dependencies.Dependencies() {
+		c.jobDependency.WithLabelValues(dependency.Upstream, dependency.Downstream).Set(1)
+	}
+	c.graphMutex.Lock()
+	c.dependencies = dependencies
+	c.graphMutex.Unlock()
+
+	// Only the jobs at the end of a chain, so every chain is reported once
+	for _, job := range triggeredJobs {
+		if dependencies.HasDownstream(job.FullName()) {
+			continue
+		}
+		chain := jenkins.TraceChain(c.opts.Root, job, job.LastBuild)
+		if len(chain) < 2 {
+			continue
+		}
+		c.chainLeadTime.WithLabelValues(jobLabelValues(job, chain[0].Job)...).Set(chain.LeadTime().Seconds())
+		for _, hop := range chain.Hops() {
+			c.chainHopWait.WithLabelValues(jobLabelValues(job, hop.Upstream, hop.Downstream)...).Set(hop.Wait.Seconds())
+		}
+	}
+
+	forecastStart := startTime.Truncate(time.Hour)
+	for label, demand := range jenkins.ForecastDemand(scheduledJobs, forecastStart, 24) {
+		for i, builds := range demand {
+			hour := forecastStart.Add(time.Duration(i) * time.Hour).UTC().Format("15")
+			c.demandForecast.WithLabelValues(label, hour).Set(builds)
+		}
+	}
+
+	builtInExecutors, ok := c.collectController()
+	c.collectPlugins()
+	c.collectNodes(nodes, builtInExecutors, ok, startTime)
+	c.collectQueue(startTime)
+	scanned := c.collectDiskUsage()
+
+	for _, indexing := range tree.Indexings {
+		c.indexingResult.WithLabelValues(indexing.Folder, indexing.Name, indexing.Result).Set(1)
+		c.indexingTimestamp.WithLabelValues(indexing.Folder, indexing.Name).Set(float64(indexing.Timestamp) / 1000)
+		c.indexingDuration.WithLabelValues(indexing.Folder, indexing.Name).Set(float64(indexing.Duration) / 1000)
+	}
 
 	c.collectFailures.Collect(ch)
+	c.controllerInfo.Collect(ch)
+	c.controllerExecutor.Collect(ch)
+	c.pluginInfo.Collect(ch)
+	c.nodeInfo.Collect(ch)
+	c.nodeExecutors.Collect(ch)
+	c.nodeLabels.Collect(ch)
+	c.nodeOffline.Collect(ch)
+	c.nodeBuilds.Collect(ch)
+	c.nodeBuildTime.Collect(ch)
+	c.nodeBusyTime.Collect(ch)
+	c.nodeUtilisation.Collect(ch)
+	c.nodePeakBuilds.Collect(ch)
+	c.queueItems.Collect(ch)
+	c.queueOldestAge.Collect(ch)
+	c.queueSnapshotTime.Collect(ch)
+	c.queueStale.Collect(ch)
+	c.jobDiskBytes.Collect(ch)
+	// the summaries of the disk usage scan don't exist when the scans are disabled or none has completed yet
+	if scanned {
+		c.diskScanTimestamp.Collect(ch)
+		c.diskScanDuration.Collect(ch)
+		c.orphanedWorkspaces.Collect(ch)
+		c.orphanedBytes.Collect(ch)
+		c.staleJobDirs.Collect(ch)
+		c.staleJobDirBytes.Collect(ch)
+	}
 	c.lastBuildNumber.Collect(ch)
 	c.lastBuildDuration.Collect(ch)
 	c.lastBuildTimestamp.Collect(ch)
+	c.pipelineFailure.Collect(ch)
+	c.pipelineFailures.Collect(ch)
+	c.coverageRatio.Collect(ch)
+	c.analysisIssues.Collect(ch)
+	c.indexingResult.Collect(ch)
+	c.indexingTimestamp.Collect(ch)
+	c.indexingDuration.Collect(ch)
+	c.jobInfo.Collect(ch)
+	c.jobDisabled.Collect(ch)
+	c.scmInfo.Collect(ch)
+	c.parameterInfo.Collect(ch)
+	c.jobConcurrentBuild.Collect(ch)
+	c.folderJobs.Collect(ch)
+	c.folderJobCount.Collect(ch)
+	c.folderFailedJobs.Collect(ch)
+	c.folderDisabledJobs.Collect(ch)
+	c.folderWorstHealth.Collect(ch)
+	c.folderBuildTime.Collect(ch)
+	c.healthScore.Collect(ch)
+	c.retainedBuilds.Collect(ch)
+	c.discarder.Collect(ch)
+	c.buildsBytes.Collect(ch)
+	c.nextBuildNumber.Collect(ch)
+	c.buildNumberGap.Collect(ch)
+	c.nextScheduled.Collect(ch)
+	c.missedSchedule.Collect(ch)
+	c.demandForecast.Collect(ch)
+	c.jobDependency.Collect(ch)
+	c.chainLeadTime.Collect(ch)
+	c.chainHopWait.Collect(ch)
 
 	for _, cg := range c.customGauges {
 		cg.Collect(ch)
 	}
 }
 
+// ServeDependencies serves the job dependency graph of the last collection, as JSON or as DOT with ?format=dot.
+func (c *Collector) ServeDependencies(w http.ResponseWriter, r *http.Request) {
+	c.graphMutex.Lock()
+	dependencies := c.dependencies
+	c.graphMutex.Unlock()
+
+	var err error
+	switch r.URL.Query().Get("format") {
+	case "dot":
+		w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+		err = dependencies.WriteDOT(w)
+	case "", "json":
+		w.Header().Set("Content-Type", "application/json")
+		err = dependencies.WriteJSON(w)
+	default:
+		http.Error(w, "unknown format, expected json or dot", http.StatusBadRequest)
+		return
+	}
+
+	if err != nil {
+		log.Errorf("serving the dependency graph failed: %v", err)
+	}
+}
+
+// collectController exports the global configuration of the Jenkins controller, and returns the number of executors of
+// the built-in node.
+func (c *Collector) collectController() (int, bool) {
+	controller, err := jenkins.ParseController(c.opts.Root)
+	if err != nil {
+		log.Errorf("parsing the controller configuration failed: %v", err)
+		return 0, false
+	}
+
+	c.controllerInfo.WithLabelValues(controller.Version, controller.SecurityRealm, controller.AuthorizationStrategy).Set(1)
+	c.controllerExecutor.Set(float64(controller.NumExecutors))
+
+	return controller.NumExecutors, true
+}
+
+// collectPlugins exports the inventory of the plugins folder.
+func (c *Collector) collectPlugins() {
+	plugins, err := jenkins.GetPlugins(c.opts.Root)
+	if err != nil {
+		log.Errorf("collecting plugins failed: %v", err)
+		return
+	}
+
+	for _, plugin := range plugins {
+		c.pluginInfo.WithLabelValues(
+			plugin.ShortName,
+			plugin.Version,
+			plugin.RequiredCoreVersion,
+			strconv.FormatBool(plugin.Disabled),
+			strconv.FormatBool(plugin.Pinned),
+			strconv.FormatBool(plugin.VersionMismatch()),
+		).Set(1)
+	}
+}
+
+// collectNodes exports the agents configured in the nodes folder, and the builds that ran on every node and how busy
+// they kept its executors.
+func (c *Collector) collectNodes(stats *jenkins.NodeAggregator, builtInExecutors int, builtInKnown bool, now time.Time) {
+	// the number of executors of every node, if it is known
+	executors := make(map[string]int)
+	if builtInKnown {
+		executors[jenkins.BuiltInNode] = builtInExecutors
+	}
+
+	for _, s := range stats.Stats() {
+		for result, builds := range s.Builds {
+			c.nodeBuilds.WithLabelValues(s.Node, result).Set(float64(builds))
+		}
+		c.nodeBuildTime.WithLabelValues(s.Node).Set(s.BuildDuration.Seconds())
+		if _, ok := executors[s.Node]; !ok {
+			executors[s.Node] = -1
+		}
+	}
+
+	nodes, err := jenkins.GetNodes(c.opts.Root)
+	if err != nil {
+		log.Errorf("collecting nodes failed: %v", err)
+	}
+
+	for _, node := range nodes {
+		c.nodeInfo.WithLabelValues(node.Name, node.Launcher).Set(1)
+		c.nodeExecutors.WithLabelValues(node.Name).Set(float64(node.NumExecutors))
+		for _, label := range node.Labels {
+			c.nodeLabels.WithLabelValues(node.Name, label).Set(1)
+		}
+		c.nodeOffline.WithLabelValues(node.Name).Set(boolToFloat(node.TemporarilyOffline))
+		executors[node.Name] = node.NumExecutors
+	}
+
+	for node, n := range executors {
+		for _, window := range c.utilisationWindows {
+			utilisation := stats.Utilisation(node, now, window.duration)
+			c.nodeBusyTime.WithLabelValues(node, window.name).Set(utilisation.BusyTime.Seconds())
+			c.nodePeakBuilds.WithLabelValues(node, window.name).Set(float64(utilisation.PeakConcurrency))
+			// agents that are gone from the nodes folder, like agents of clouds, have an unknown number of executors
+			if n >= 0 {
+				c.nodeUtilisation.WithLabelValues(node, window.name).Set(utilisation.Ratio(n))
+			}
+		}
+	}
+}
+
+// collectQueue exports the queue snapshot in queue.xml. The items of a stale snapshot aren't exported, since they were
+// most likely built or cancelled long ago, and a missing snapshot is reported as stale.
+func (c *Collector) collectQueue(now time.Time) {
+	// without a snapshot that describes the current queue, nothing is known to be waiting
+	c.queueOldestAge.Set(0)
+
+	queue, err := jenkins.ParseQueue(c.opts.Root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			log.Debugf("no queue snapshot found: %v", err)
+		} else {
+			log.Errorf("parsing the queue snapshot failed: %v", err)
+		}
+		c.queueSnapshotTime.Set(0)
+		c.queueStale.Set(1)
+		return
+	}
+
+	stale := queue.Stale(now, c.queueMaxAge)
+	c.queueSnapshotTime.Set(float64(queue.ModTime.UnixNano()) / float64(time.Second))
+	c.queueStale.Set(boolToFloat(stale))
+	if stale {
+		log.Debugf("queue snapshot of %v is stale", queue.ModTime)
+		return
+	}
+
+	for _, item := range queue.Items {
+		c.queueItems.WithLabelValues(jobLabelValues(item.Job, item.State)...).Inc()
+	}
+	if oldest, ok := queue.Oldest(); ok {
+		c.queueOldestAge.Set(now.Sub(oldest.InQueueSince).Seconds())
+	}
+}
+
+// collectDiskUsage exports the result of the last disk usage scan, when the scans are enabled and one has completed.
+// It returns whether there was such a scan.
+func (c *Collector) collectDiskUsage() bool {
+	if c.diskScanner == nil {
+		return false
+	}
+
+	usage := c.diskScanner.Usage()
+	if usage.Timestamp.IsZero() {
+		return false
+	}
+
+	c.diskScanTimestamp.Set(float64(usage.Timestamp.UnixNano()) / float64(time.Second))
+	c.diskScanDuration.Set(usage.Duration.Seconds())
+	for _, builds := range usage.Builds {
+		c.buildsBytes.WithLabelValues(jobLabelValues(builds.Job)...).Set(float64(builds.Bytes))
+	}
+	for _, job := range jenkins.TopJobsPerFolder(usage.Jobs, c.diskTopJobs) {
+		for kind, bytes := range job.Bytes {
+			c.jobDiskBytes.WithLabelValues(job.Folder, job.Name, kind).Set(float64(bytes))
+		}
+	}
+
+	var orphanedBytes int64
+	for _, workspace := range usage.OrphanedWorkspaces {
+		orphanedBytes += workspace.Bytes
+	}
+	c.orphanedWorkspaces.Set(float64(len(usage.OrphanedWorkspaces)))
+	c.orphanedBytes.Set(float64(orphanedBytes))
+
+	var staleBytes int64
+	for _, dir := range usage.StaleJobDirs {
+		staleBytes += dir.Bytes
+	}
+	c.staleJobDirs.Set(float64(len(usage.StaleJobDirs)))
+	c.staleJobDirBytes.Set(float64(staleBytes))
+
+	return true
+}
+
+// scanDiskUsage scans the disk usage of the builds of all jobs every interval, until the exporter stops.
+func scanDiskUsage(scanner *jenkins.DiskScanner, interval time.Duration) {
+	for {
+		log.Info("Started disk usage scan")
+		if err := scanner.Scan(); err != nil {
+			log.Errorf("scanning disk usage failed: %v", err)
+		} else {
+			log.Infof("Disk usage scan completed in %f seconds", scanner.Usage().Duration.Seconds())
+		}
+		time.Sleep(interval)
+	}
+}
+
+// collectSchedules exports when the triggers of the job fire next, and whether its timer trigger missed a build.
+func (c *Collector) collectSchedules(job jenkins.Job, now time.Time) {
+	for _, trigger := range job.Config.Triggers {
+		schedule, err := jenkins.ParseCronTab(trigger.Spec, job.FullName())
+		if err != nil {
+			log.Debugf("Couldn't parse %s trigger of %s: %v", trigger.Type, job.FullName(), err)
+			continue
+		}
+
+		if next, ok := schedule.Next(now); ok {
+			c.nextScheduled.WithLabelValues(jobLabelValues(job, trigger.Type)...).Set(float64(next.Unix()))
+		}
+
+		// SCM polling only starts a build when there are changes, so it can't miss one
+		if trigger.Type == jenkins.TimerTrigger {
+			c.missedSchedule.WithLabelValues(jobLabelValues(job)...).Set(boolToFloat(job.MissedSchedule(schedule, now, c.scheduleGrace)))
+		}
+	}
+}
+
+// jobLabelNames returns the labels identifying a job, followed by the given metric specific labels.
+// Sub-jobs such as matrix configurations, Maven modules and multibranch branches carry the name of their parent job
+// and are told apart by the extra labels.
+func jobLabelNames(labels ...string) []string {
+	return append([]string{"folder", "jenkins_job", "axes", "module", "branch", "is_pull_request"}, labels...)
+}
+
+// jobLabelValues returns the values for the labels returned by jobLabelNames.
+func jobLabelValues(job jenkins.Job, values ...string) []string {
+	isPullRequest := ""
+	if job.Branch != "" {
+		isPullRequest = strconv.FormatBool(job.IsPullRequest)
+	}
+	return append([]string{job.Folder, job.Name, job.Axes, job.Module, job.Branch, isPullRequest}, values...)
+}
+
 func doParse(jobPaths <-chan jenkins.JobPath, jobs chan<- jenkins.Job) {
 	for jobPath := range jobPaths {
 		job, err := jobPath.Parse()
-		if err != nil {
+		if err != nil && !errors.Is(err, jenkins.ErrNoBuilds) {
 			log.Debugf("Failed to parse %s: %v", jobPath, err)
 			continue
 		}
@@ -219,6 +1217,13 @@ func doParse(jobPaths <-chan jenkins.JobPath, jobs chan<- jenkins.Job) {
 	}
 }
 
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
 func createCustomGauges(input string) (map[string]*prometheus.GaugeVec, error) {
 
 	customGauges := make(map[string]*prometheus.GaugeVec)
@@ -245,7 +1250,7 @@ func createCustomGauges(input string) (map[string]*prometheus.GaugeVec, error) {
 				Name:      fmt.Sprintf("custom_last_%s", metricName),
 				Help:      fmt.Sprintf("Custom metric generated from environment variable %s", envVar),
 			},
-			[]string{"folder", "jenkins_job", "result"},
+			jobLabelNames("result"),
 		)
 		log.Infof("Added custom metric custom_last_%s using %s", metricName, envVar)
 	}
@@ -253,7 +1258,7 @@ func createCustomGauges(input string) (map[string]*prometheus.GaugeVec, error) {
 	return customGauges, nil
 }
 
-func populateCustomGauges(folder, job, result string, build jenkins.Build, customGauges map[string]*prometheus.GaugeVec) {
+func populateCustomGauges(labelValues []string, build jenkins.Build, customGauges map[string]*prometheus.GaugeVec) {
 	for ev, cg := range customGauges {
 		val, ok := build.EnvVars[ev]
 		if !ok {
@@ -264,8 +1269,67 @@ func populateCustomGauges(folder, job, result string, build jenkins.Build, custo
 			log.Debugf("Couldn't parse environment variable %s: %v", ev, err)
 			continue
 		}
-		cg.WithLabelValues(folder, job, result).Set(parsed)
+		cg.WithLabelValues(labelValues...).Set(parsed)
+	}
+}
+
+// reportOrphanedWorkspaces prints the workspaces of jobs that don't exist anymore, followed by the stale job folders,
+// largest first.
+func reportOrphanedWorkspaces(w io.Writer, root string) error {
+	workspaces, err := jenkins.FindOrphanedWorkspaces(root, 0)
+	if err != nil {
+		return err
+	}
+	staleDirs, err := jenkins.FindStaleJobDirs(root, 0)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "BYTES\tJOB\tPATH")
+	var total int64
+	for _, workspace := range workspaces {
+		fmt.Fprintf(tw, "%d\t%s\t%s\n", workspace.Bytes, workspace.Job, workspace.Path)
+		total += workspace.Bytes
+	}
+	fmt.Fprintf(tw, "%d\ttotal\t%d workspaces\n", total, len(workspaces))
+
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "BYTES\tJOB\tFOLDER")
+	total = 0
+	for _, dir := range staleDirs {
+		fmt.Fprintf(tw, "%d\t%s\t%s\n", dir.Bytes, dir.Job, dir.Path)
+		total += dir.Bytes
+	}
+	fmt.Fprintf(tw, "%d\ttotal\t%d stale job folders\n", total, len(staleDirs))
+
+	return tw.Flush()
+}
+
+type utilisationWindow struct {
+	name     string
+	duration time.Duration
+}
+
+// parseUtilisationWindows parses the comma-separated durations of the windows to compute the executor utilisation over.
+// The durations are used as the window label as they are written.
+func parseUtilisationWindows(config string) ([]utilisationWindow, error) {
+	var windows []utilisationWindow
+	for _, name := range strings.Split(config, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(name)
+		if err != nil {
+			return nil, err
+		}
+		if duration <= 0 {
+			return nil, fmt.Errorf("window %s is not positive", name)
+		}
+		windows = append(windows, utilisationWindow{name, duration})
 	}
+	return windows, nil
 }
 
 func main() {
@@ -273,12 +1337,26 @@ func main() {
 	flag.Parse()
 
 	log.Base().SetLevel(*logLevel)
+
+	if *orphans {
+		if err := reportOrphanedWorkspaces(os.Stdout, *jenkinsPath); err != nil {
+			log.Fatalf("Error finding orphaned workspaces: %v", err)
+		}
+		return
+	}
+
 	log.Infoln("Starting Jenkins exporter", version.Info())
 	log.Infoln("Build context", version.BuildContext())
 
+	// the builds folder only changes when Jenkins restarts, so it's only checked once
+	if controller, err := jenkins.ParseController(*jenkinsPath); err == nil && controller.BuildsDir != jenkins.DefaultBuildsDir {
+		log.Warnf("builds are stored in %s, only builds in the job folders are exported", controller.BuildsDir)
+	}
+
 	opts := &jenkins.JobPathOpts{
-		Root:       *jenkinsPath,
-		IgnoreList: strings.Split(*ignoreList, ","),
+		Root:         *jenkinsPath,
+		IgnoreList:   strings.Split(*ignoreList, ","),
+		MavenModules: *modules,
 	}
 
 	collector := NewCollector(*opts)
@@ -288,17 +1366,32 @@ func main() {
 		log.Fatalf("Error parsing custom metrics config: %v", err)
 	}
 	collector.customGauges = customMetrics
+	collector.scheduleGrace = *grace
+	collector.queueMaxAge = *queueAge
+	collector.diskTopJobs = *diskTopJobs
+
+	if *diskScan > 0 {
+		collector.diskScanner = jenkins.NewDiskScanner(*opts, *diskRate)
+		go scanDiskUsage(collector.diskScanner, *diskScan)
+	}
+
+	collector.utilisationWindows, err = parseUtilisationWindows(*windows)
+	if err != nil {
+		log.Fatalf("Error parsing utilisation windows: %v", err)
+	}
 
 	prometheus.MustRegister(collector)
 	prometheus.MustRegister(version.NewCollector("jenkins_exporter"))
 
 	http.Handle(*path, promhttp.Handler())
+	http.HandleFunc(*depsPath, collector.ServeDependencies)
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte(`<html>
 			 <head><title>Jenkins Exporter</title></head>
 			 <body>
 			 <h1>Jenkins Exporter</h1>
 			 <p><a href='` + *path + `'>Metrics</a></p>
+			 <p><a href='` + *depsPath + `'>Job dependencies</a> (<a href='` + *depsPath + `?format=dot'>DOT</a>)</p>
 			 </body>
 			 </html>`))
 	})